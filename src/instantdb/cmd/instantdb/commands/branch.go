@@ -0,0 +1,248 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	branchFile string
+	branchGone bool
+)
+
+// BranchCmd returns the branch command
+func BranchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "List and drop the per-branch copies of project instances",
+		Long: `Instances declared with branches: true in instantdb.yaml get a copy for every git
+branch other than the default one, created by instant-db up or when url, env
+and the other instance commands first resolve the instance. These commands
+manage the copies of the project in the current directory.`,
+	}
+	cmd.PersistentFlags().StringVarP(&branchFile, "file", "f", "", "Path to the manifest (default: closest instantdb.yaml)")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List branch copies and whether their git branch still exists",
+		Args:  cobra.NoArgs,
+		RunE:  runBranchList,
+	})
+
+	drop := &cobra.Command{
+		Use:   "drop [branch...]",
+		Short: "Stop and delete the copies of branches",
+		RunE:  runBranchDrop,
+	}
+	drop.Flags().BoolVar(&branchGone, "gone", false, "Drop the copies of branches that no longer exist in git")
+	cmd.AddCommand(drop)
+
+	return cmd
+}
+
+func runBranchList(cmd *cobra.Command, args []string) error {
+	manifest, instances, err := loadBranchInstances()
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 {
+		fmt.Println(ui.MutedStyle.Render(fmt.Sprintf("No branch copies in %s.\n", manifest.Project)))
+		return nil
+	}
+
+	current, err := utils.GitBranch(manifest.Dir)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	fmt.Println(ui.TitleStyle.Render(fmt.Sprintf("🌿 Branch copies of %s (%d)", manifest.Project, len(instances))) + "\n")
+	for _, instance := range instances {
+		// The saved state is only shown when the engine cannot be asked
+		state := instance.Status
+		if instance.Paused {
+			state = types.StatePaused
+		}
+		if engine, err := GetEngine(instance.Engine); err == nil {
+			if status, err := engine.Status(ctx, instance.ID); err == nil {
+				state = status.State
+			}
+		}
+
+		var notes []string
+		if instance.Branch == current {
+			notes = append(notes, "checked out")
+		}
+		if exists, err := utils.GitBranchExists(manifest.Dir, instance.Branch); err == nil && !exists {
+			notes = append(notes, "branch deleted")
+		}
+
+		line := fmt.Sprintf("  • %s (%s)", instance.Name, state)
+		if len(notes) > 0 {
+			line += ui.MutedStyle.Render(" - " + strings.Join(notes, ", "))
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func runBranchDrop(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if len(args) == 0 && !branchGone {
+		return fmt.Errorf("name the branches to drop, or use --gone")
+	}
+
+	manifest, instances, err := loadBranchInstances()
+	if err != nil {
+		return err
+	}
+
+	selected := make(map[string]bool)
+	for _, branch := range args {
+		selected[branch] = true
+	}
+
+	var results []projectResult
+	for _, instance := range instances {
+		drop := selected[instance.Branch]
+		if branchGone && !drop {
+			exists, err := utils.GitBranchExists(manifest.Dir, instance.Branch)
+			if err != nil {
+				return err
+			}
+			drop = !exists
+		}
+		if drop {
+			results = append(results, dropBranchInstance(ctx, instance))
+		}
+	}
+
+	if len(results) == 0 {
+		fmt.Println(ui.MutedStyle.Render("No branch copies to drop.\n"))
+		return nil
+	}
+	return printProjectResults(results)
+}
+
+// loadBranchInstances returns the manifest and the branch copies of its instances, sorted by name
+func loadBranchInstances() (*types.Manifest, []*types.Instance, error) {
+	manifest, err := loadProjectManifest(branchFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	instances, err := utils.ListProjectInstances(manifest.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var copies []*types.Instance
+	for _, instance := range instances {
+		if instance.Branch != "" {
+			copies = append(copies, instance)
+		}
+	}
+	sort.Slice(copies, func(i, j int) bool {
+		return copies[i].Name < copies[j].Name
+	})
+
+	return manifest, copies, nil
+}
+
+// dropBranchInstance stops a branch copy and deletes its data, persistent or not
+func dropBranchInstance(ctx context.Context, instance *types.Instance) projectResult {
+	result := projectResult{name: instance.Name}
+
+	engine, err := GetEngine(instance.Engine)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if err := engine.Stop(ctx, instance.ID); err != nil {
+		result.err = fmt.Errorf("failed to stop: %w", err)
+		return result
+	}
+	if err := os.RemoveAll(instance.DataDir); err != nil {
+		result.err = fmt.Errorf("failed to remove data directory: %w", err)
+		return result
+	}
+
+	result.action = "dropped"
+	return result
+}
+
+// resolveProjectInstance resolves an instance name or ID like utils.ResolveInstance,
+// except that the name of a project instance declared with branches: true
+// resolves to its copy for the git branch checked out in the project. A
+// missing copy is cloned from the instance and a paused one resumed, as up does.
+func resolveProjectInstance(nameOrID string) (string, error) {
+	instanceID, err := utils.ResolveInstance(nameOrID)
+	if err != nil {
+		return "", err
+	}
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil || instance.Name != nameOrID || instance.Project == "" || instance.Branch != "" {
+		return instanceID, nil
+	}
+
+	// Without its manifest the instance is used as it is
+	manifest, err := utils.LoadManifest(filepath.Join(instance.Project, utils.ManifestFileName))
+	if err != nil {
+		return instanceID, nil
+	}
+	name := strings.TrimPrefix(instance.Name, manifest.Project+"/")
+	if !manifest.Instances[name].Branches {
+		return instanceID, nil
+	}
+
+	branch, err := utils.ProjectBranch(manifest)
+	if err != nil || branch == "" {
+		return instanceID, err
+	}
+
+	branchCopy, err := findInstanceByName(utils.BranchInstanceName(manifest, name, branch))
+	if err != nil {
+		return "", err
+	}
+	if branchCopy != nil && !branchCopy.Paused {
+		return branchCopy.ID, nil
+	}
+
+	def, err := engines.Lookup(instance.Engine)
+	if err != nil {
+		return "", err
+	}
+	engine, err := GetEngine(def.Name)
+	if err != nil {
+		return "", err
+	}
+	result := upBranchInstance(context.Background(), engine, manifest, name, def, branch, instance)
+	if result.err != nil {
+		return "", fmt.Errorf("failed to bring up %s: %w", result.name, result.err)
+	}
+
+	// Standard output may be captured, as in $(instant-db url db)
+	fmt.Fprintln(os.Stderr, ui.MutedStyle.Render(fmt.Sprintf("🌿 %s: %s", result.name, result.action)))
+
+	branchCopy, err = findInstanceByName(result.name)
+	if err != nil {
+		return "", err
+	}
+	if branchCopy == nil {
+		return "", fmt.Errorf("instance not found: %s", result.name)
+	}
+	return branchCopy.ID, nil
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cloneName    string
+	clonePort    int
+	clonePersist bool
+)
+
+// CloneCmd returns the clone command
+func CloneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clone <source-name-or-id> --name <new-name>",
+		Short: "Copy an instance into a new independent instance",
+		Long: `Create a new instance of the same engine with a copy of the source's data and
+start it next to the source. A running source is paused while its data is copied.
+
+On filesystems with reflinks (btrfs, xfs) the copy is copy-on-write, so even
+large databases clone in seconds.`,
+		Example: `  instant-db clone shop --name shop-experiment`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    runClone,
+	}
+
+	cmd.Flags().StringVarP(&cloneName, "name", "n", "", "Name of the new instance")
+	cmd.Flags().IntVarP(&clonePort, "port", "p", 0, "Port number (auto-assigned if not specified)")
+	cmd.Flags().BoolVar(&clonePersist, "persist", false, "Keep data after stop")
+	cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+func runClone(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	source, engine, err := loadInstanceAndEngine(args[0])
+	if err != nil {
+		return err
+	}
+
+	var clone *types.Instance
+	err = ui.ShowSpinner(fmt.Sprintf("Cloning %s into %s", source.Name, cloneName), func() error {
+		var err error
+		clone, err = cloneInstance(ctx, engine, source, engines.CloneOptions{Name: cloneName, Port: clonePort, Persist: clonePersist})
+		return err
+	})
+	if err != nil {
+		printLogTail(err)
+		return fmt.Errorf("failed to clone instance: %w", err)
+	}
+
+	fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Cloned %s into %s\n", source.Name, clone.Name)))
+	fmt.Printf("  Instance ID:       %s\n", clone.ID)
+	fmt.Printf("  Port:              %d\n", clone.Port)
+	if url, err := engine.GetConnectionURL(clone.ID); err == nil {
+		fmt.Printf("  Connection String: %s\n\n", url)
+	}
+	fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("💡 Stop instance: instant-db stop %s\n", clone.Name)))
+	return nil
+}
+
+// cloneInstance clones an instance with engines.Clone, relocating the copied
+// data when the engine needs it
+func cloneInstance(ctx context.Context, engine engines.Engine, source *types.Instance, options engines.CloneOptions) (*types.Instance, error) {
+	if local, err := localEngine(source.Engine); err == nil {
+		options.Relocator, _ = local.(engines.Relocator)
+	}
+	return engines.Clone(ctx, engine, source, options)
+}
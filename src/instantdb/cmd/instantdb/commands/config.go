@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd returns the config command
+func ConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show or change default settings",
+		Long: fmt.Sprintf(`Read and write config.yaml in the instant-db home directory. Its settings are
+used by start whenever the matching flag is not given.
+
+Keys:
+  %s`, strings.Join(utils.ConfigKeys, "\n  ")),
+		// Invalid settings must not stop config from repairing them
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return initEngine(false)
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runConfigGet,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting (an empty value unsets it)",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runConfigSet,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List all settings that are set",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigList,
+	})
+
+	return cmd
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	key, err := canonicalConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+
+	value, err := utils.GetConfigValue(userConfig, key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, err := canonicalConfigKey(args[0])
+	if err != nil {
+		return err
+	}
+
+	value := args[1]
+	if key == "default_engine" && value != "" {
+		def, err := engines.Lookup(value)
+		if err != nil {
+			return err
+		}
+		value = def.Name
+	}
+
+	if err := utils.SetConfigValue(userConfig, key, value); err != nil {
+		return err
+	}
+	if err := utils.SaveUserConfig(userConfig); err != nil {
+		return err
+	}
+
+	if value == "" {
+		fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Unset %s\n", key)))
+	} else {
+		fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Set %s = %s\n", key, value)))
+	}
+
+	return nil
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	values := utils.ConfigValues(userConfig)
+	if len(values) == 0 {
+		path, _ := utils.ConfigPath()
+		fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("💡 No settings in %s. Set one: instant-db config set default_engine postgres\n", path)))
+		return nil
+	}
+
+	for _, value := range values {
+		fmt.Printf("%s = %s\n", value.Key, value.Value)
+	}
+
+	return nil
+}
+
+// canonicalConfigKey rewrites engine aliases in engines.<engine>.<field> keys
+// to the canonical engine name
+func canonicalConfigKey(key string) (string, error) {
+	engine, field, ok := utils.SplitEngineKey(key)
+	if !ok {
+		return key, nil
+	}
+
+	def, err := engines.Lookup(engine)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("engines.%s.%s", def.Name, field), nil
+}
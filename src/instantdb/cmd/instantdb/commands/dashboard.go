@@ -0,0 +1,532 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// dashboardRefreshInterval is how often the dashboard reloads every instance
+	dashboardRefreshInterval = 2 * time.Second
+
+	// dashboardStatusTimeout bounds the status checks of a single refresh
+	dashboardStatusTimeout = 5 * time.Second
+
+	// dashboardNameWidth is the width instance names are cut to
+	dashboardNameWidth = 28
+)
+
+const dashboardHelp = "↑/↓ select • p pause • r resume • s stop • c copy URL • l logs • enter shell • q quit"
+
+// DashboardCmd returns the dashboard command
+func DashboardCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "dashboard",
+		Short: "Watch and manage every instance in a live full-screen view",
+		Long: `Show every instance with its engine, port, live state, uptime and disk usage,
+refreshed every two seconds. The selected instance can be paused, resumed or
+stopped, its connection URL copied, and its logs or a shell opened; quitting
+the logs or shell returns to the dashboard.`,
+		Args: cobra.NoArgs,
+		RunE: runDashboard,
+	}
+}
+
+func runDashboard(cmd *cobra.Command, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate instant-db executable: %w", err)
+	}
+	home, err := utils.HomeDir()
+	if err != nil {
+		return err
+	}
+
+	program := tea.NewProgram(&dashboardModel{executable: executable, home: home}, tea.WithAltScreen(), tea.WithoutSignalHandler())
+
+	// Ctrl+C reaches the dashboard as a key press; a signal is meant for the
+	// logs or shell running in front of it
+	restore := ui.HandleInterrupts(func(sig os.Signal) {
+		if sig != os.Interrupt {
+			program.Quit()
+		}
+	})
+	defer restore()
+
+	_, err = program.Run()
+	return err
+}
+
+// dashboardRow is an instance as last seen by the dashboard
+type dashboardRow struct {
+	instance *types.Instance
+	status   *types.Status
+	size     int64
+}
+
+// state returns the live state of the instance, or its saved one when the
+// status check failed
+func (r dashboardRow) state() string {
+	if r.status != nil && r.status.State != "" {
+		return r.status.State
+	}
+	if r.instance.Paused {
+		return types.StatePaused
+	}
+	return r.instance.Status
+}
+
+type dashboardRefreshMsg struct {
+	rows    []dashboardRow
+	corrupt int
+	err     error
+}
+
+type dashboardTickMsg struct{}
+
+// dashboardActionMsg reports the outcome of a key press acting on an instance
+type dashboardActionMsg struct {
+	message string
+	err     error
+}
+
+type dashboardModel struct {
+	executable string
+	home       string
+
+	rows       []dashboardRow
+	corrupt    int
+	selected   string
+	refreshed  time.Time
+	refreshing bool
+	err        error
+
+	// confirmStop is the instance a second s press stops
+	confirmStop string
+
+	message string
+	failed  bool
+}
+
+func (m *dashboardModel) Init() tea.Cmd {
+	m.refreshing = true
+	return tea.Batch(dashboardRefresh, dashboardTick())
+}
+
+func dashboardTick() tea.Cmd {
+	return tea.Tick(dashboardRefreshInterval, func(time.Time) tea.Msg {
+		return dashboardTickMsg{}
+	})
+}
+
+// dashboardRefresh loads every instance with its live status and disk usage
+func dashboardRefresh() tea.Msg {
+	instances, err := utils.ListInstances()
+	if err != nil {
+		return dashboardRefreshMsg{err: err}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Name < instances[j].Name
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), dashboardStatusTimeout)
+	defer cancel()
+
+	rows := make([]dashboardRow, len(instances))
+	for i, instance := range instances {
+		rows[i].instance = instance
+		if engine, err := GetEngine(instance.Engine); err == nil {
+			if status, err := engine.Status(ctx, instance.ID); err == nil {
+				rows[i].status = status
+			}
+		}
+		rows[i].size, _ = utils.DirSize(instance.DataDir)
+	}
+
+	msg := dashboardRefreshMsg{rows: rows}
+	if corrupt, err := utils.CheckInstances(); err == nil {
+		msg.corrupt = len(corrupt)
+	}
+	return msg
+}
+
+func (m *dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case dashboardTickMsg:
+		if m.refreshing {
+			return m, dashboardTick()
+		}
+		m.refreshing = true
+		return m, tea.Batch(dashboardRefresh, dashboardTick())
+	case dashboardRefreshMsg:
+		m.refreshing = false
+		m.refreshed = time.Now()
+		m.err = msg.err
+		if msg.err == nil {
+			m.rows = msg.rows
+			m.corrupt = msg.corrupt
+		}
+		if m.index() < 0 && len(m.rows) > 0 {
+			m.selected = m.rows[0].instance.ID
+		}
+	case dashboardActionMsg:
+		m.message, m.failed = msg.message, msg.err != nil
+		if msg.err != nil {
+			m.message = fmt.Sprintf("%s: %v", msg.message, msg.err)
+		}
+		// Show the effect of the action right away
+		if !m.refreshing {
+			m.refreshing = true
+			return m, dashboardRefresh
+		}
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *dashboardModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	confirmStop := m.confirmStop
+	m.confirmStop = ""
+
+	switch key {
+	case "q", "esc", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		m.move(-1)
+		return m, nil
+	case "down", "j":
+		m.move(1)
+		return m, nil
+	}
+
+	index := m.index()
+	if index < 0 {
+		return m, nil
+	}
+	row := m.rows[index]
+	instance := row.instance
+
+	switch key {
+	case "p":
+		if row.state() != types.StateRunning {
+			return m, m.notice(fmt.Sprintf("%s is not running", instance.Name))
+		}
+		m.message, m.failed = fmt.Sprintf("Pausing %s...", instance.Name), false
+		return m, dashboardEngineAction(instance, "Paused", "Failed to pause", func(ctx context.Context, engine engines.Engine) error {
+			return engine.Pause(ctx, instance.ID)
+		})
+	case "r":
+		if row.state() != types.StatePaused {
+			return m, m.notice(fmt.Sprintf("%s is not paused", instance.Name))
+		}
+		m.message, m.failed = fmt.Sprintf("Resuming %s...", instance.Name), false
+		return m, dashboardEngineAction(instance, "Resumed", "Failed to resume", func(ctx context.Context, engine engines.Engine) error {
+			return engine.Resume(ctx, instance.ID)
+		})
+	case "s":
+		if confirmStop != instance.ID {
+			m.confirmStop = instance.ID
+			warning := fmt.Sprintf("Press s again to stop %s", instance.Name)
+			if !instance.Persist {
+				warning += " and delete its data"
+			}
+			m.message, m.failed = warning, true
+			return m, nil
+		}
+		m.message, m.failed = fmt.Sprintf("Stopping %s...", instance.Name), false
+		return m, dashboardEngineAction(instance, "Stopped", "Failed to stop", func(ctx context.Context, engine engines.Engine) error {
+			return engine.Stop(ctx, instance.ID)
+		})
+	case "c":
+		return m, dashboardCopyURL(instance)
+	case "l":
+		if _, err := engines.InstanceLogFile(instance); err != nil {
+			return m, m.notice(err.Error())
+		}
+		return m, m.exec(instance, "logs", instance.ID, "-f", "--tail", "100")
+	case "enter", "x":
+		if row.state() != types.StateRunning {
+			return m, m.notice(fmt.Sprintf("%s is not running", instance.Name))
+		}
+		return m, m.exec(instance, dashboardShell(instance), instance.ID)
+	}
+	return m, nil
+}
+
+// notice shows a message that needs no action
+func (m *dashboardModel) notice(message string) tea.Cmd {
+	m.message, m.failed = message, false
+	return nil
+}
+
+// index returns the position of the selected instance, -1 when there is none
+func (m *dashboardModel) index() int {
+	for i, row := range m.rows {
+		if row.instance.ID == m.selected {
+			return i
+		}
+	}
+	return -1
+}
+
+// move moves the selection by delta rows
+func (m *dashboardModel) move(delta int) {
+	if len(m.rows) == 0 {
+		return
+	}
+	index := m.index() + delta
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(m.rows) {
+		index = len(m.rows) - 1
+	}
+	m.selected = m.rows[index].instance.ID
+}
+
+// dashboardEngineAction runs an engine operation on an instance in the background
+func dashboardEngineAction(instance *types.Instance, done, failed string, fn func(ctx context.Context, engine engines.Engine) error) tea.Cmd {
+	return func() tea.Msg {
+		engine, err := GetEngine(instance.Engine)
+		if err == nil {
+			err = fn(context.Background(), engine)
+		}
+		if err != nil {
+			return dashboardActionMsg{message: fmt.Sprintf("%s %s", failed, instance.Name), err: err}
+		}
+		return dashboardActionMsg{message: fmt.Sprintf("%s %s", done, instance.Name)}
+	}
+}
+
+// dashboardCopyURL copies the connection URL of an instance to the clipboard,
+// or shows it when there is no clipboard to copy to
+func dashboardCopyURL(instance *types.Instance) tea.Cmd {
+	return func() tea.Msg {
+		engine, err := GetEngine(instance.Engine)
+		if err != nil {
+			return dashboardActionMsg{message: "Failed to get the URL of " + instance.Name, err: err}
+		}
+		url, err := engine.GetConnectionURL(instance.ID)
+		if err != nil {
+			return dashboardActionMsg{message: "Failed to get the URL of " + instance.Name, err: err}
+		}
+		if err := clipboard.WriteAll(url); err != nil {
+			return dashboardActionMsg{message: "No clipboard available, URL: " + url}
+		}
+		return dashboardActionMsg{message: fmt.Sprintf("Copied the URL of %s", instance.Name)}
+	}
+}
+
+// dashboardShell picks the command that opens a shell on an instance: the
+// engine's own client when it is installed, the built-in prompt otherwise
+func dashboardShell(instance *types.Instance) string {
+	local, err := localEngine(instance.Engine)
+	if err != nil {
+		return "shell"
+	}
+	if shell, ok := local.(engines.Shell); ok {
+		if _, cleanup, err := shell.ShellCommand(instance, ""); err == nil {
+			cleanup()
+			return "shell"
+		}
+	}
+	if _, ok := local.(engines.Querier); ok {
+		return "query"
+	}
+	return "shell"
+}
+
+// exec hands the terminal to another instant-db command and returns to the
+// dashboard when it exits
+func (m *dashboardModel) exec(instance *types.Instance, args ...string) tea.Cmd {
+	command := exec.Command(m.executable, append([]string{"--home", m.home}, args...)...)
+	return tea.ExecProcess(command, func(err error) tea.Msg {
+		// A shell exits with the status of its last command, which is no failure
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			return dashboardActionMsg{message: fmt.Sprintf("Failed to open %s on %s", args[0], instance.Name), err: err}
+		}
+		return dashboardActionMsg{}
+	})
+}
+
+func (m *dashboardModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(ui.TitleStyle.Render(fmt.Sprintf("📊 instant-db dashboard (%d)", len(m.rows))) + "\n")
+
+	if m.err != nil {
+		b.WriteString(ui.ErrorStyle.Render(fmt.Sprintf("❌ failed to load instances: %v", m.err)) + "\n\n")
+	}
+
+	if len(m.rows) == 0 {
+		if !m.refreshed.IsZero() {
+			b.WriteString(ui.MutedStyle.Render("No instances found.") + "\n\n")
+			b.WriteString(ui.InfoStyle.Render("💡 Start a new instance: instant-db start") + "\n")
+		}
+	} else {
+		b.WriteString(m.renderTable())
+	}
+
+	if m.corrupt > 0 {
+		b.WriteString("\n" + ui.WarningStyle.Render(fmt.Sprintf("⚠️  %d instance records are corrupted and not shown. Check: instant-db doctor", m.corrupt)) + "\n")
+	}
+
+	b.WriteString("\n")
+	if m.message != "" {
+		style := ui.InfoStyle
+		if m.failed {
+			style = ui.WarningStyle
+		}
+		b.WriteString(style.Render(m.message) + "\n")
+	} else {
+		b.WriteString("\n")
+	}
+
+	footer := dashboardHelp
+	if !m.refreshed.IsZero() {
+		footer += fmt.Sprintf(" • updated %s", m.refreshed.Format("15:04:05"))
+	}
+	b.WriteString(ui.MutedStyle.Render(footer) + "\n")
+
+	return b.String()
+}
+
+// renderTable renders a line per instance and the details of the selected one
+func (m *dashboardModel) renderTable() string {
+	headers := []string{"NAME", "ENGINE", "PORT", "STATUS", "UPTIME", "DISK"}
+	cells := make([][]string, len(m.rows))
+	for i, row := range m.rows {
+		name := row.instance.Name
+		if runes := []rune(name); len(runes) > dashboardNameWidth {
+			name = string(runes[:dashboardNameWidth-1]) + "…"
+		}
+		cells[i] = []string{
+			name,
+			row.instance.Engine,
+			fmt.Sprint(row.instance.Port),
+			row.state(),
+			dashboardUptime(row),
+			ui.FormatBytes(row.size),
+		}
+	}
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = lipgloss.Width(header)
+		for _, row := range cells {
+			if w := lipgloss.Width(row[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	pad := func(text string, width int) string {
+		return text + strings.Repeat(" ", width-lipgloss.Width(text))
+	}
+
+	var b strings.Builder
+
+	var line []string
+	for i, header := range headers {
+		line = append(line, pad(header, widths[i]))
+	}
+	b.WriteString("  " + ui.LabelStyle.Render(strings.Join(line, "  ")) + "\n")
+
+	for r, row := range m.rows {
+		line = line[:0]
+		for i, cell := range cells[r] {
+			text := pad(cell, widths[i])
+			if i == 3 {
+				text = dashboardStateStyle(row.state()).Render(text)
+			}
+			line = append(line, text)
+		}
+
+		marker := "  "
+		text := strings.Join(line, "  ")
+		if row.instance.ID == m.selected {
+			marker = ui.InfoStyle.Render("▸ ")
+		}
+		b.WriteString(marker + text + "\n")
+	}
+
+	// Details of the selected instance, such as why it is not running
+	if index := m.index(); index >= 0 {
+		row := m.rows[index]
+		details := []string{row.instance.ID}
+		if row.instance.Project != "" {
+			details = append(details, "project "+row.instance.Project)
+		}
+		if row.instance.Branch != "" {
+			details = append(details, "branch "+row.instance.Branch)
+		}
+		if row.status != nil && row.status.Message != "" && row.state() != types.StateRunning {
+			details = append(details, row.status.Message)
+		}
+		b.WriteString("\n" + ui.MutedStyle.Render("  "+strings.Join(details, " • ")) + "\n")
+	}
+
+	return b.String()
+}
+
+// dashboardStateStyle colors a state the way list does
+func dashboardStateStyle(state string) lipgloss.Style {
+	switch state {
+	case types.StateRunning:
+		return ui.SuccessStyle
+	case types.StatePaused, types.StateStarting:
+		return ui.WarningStyle
+	}
+	return ui.ErrorStyle
+}
+
+// dashboardUptime renders how long the server of a running instance has been up
+func dashboardUptime(row dashboardRow) string {
+	if row.state() != types.StateRunning {
+		return "-"
+	}
+	started := row.instance.StartedAt
+	if started == 0 {
+		started = row.instance.CreatedAt
+	}
+	if started == 0 {
+		return "-"
+	}
+	return formatUptime(time.Since(time.Unix(started, 0)))
+}
+
+// formatUptime renders a duration with its two largest units
+func formatUptime(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
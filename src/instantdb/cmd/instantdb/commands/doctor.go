@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+// DoctorCmd returns the doctor command
+func DoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Detect and repair corrupted instance records",
+		Long: `Check every instance record in the instant-db home directory. With --fix,
+corrupted records are restored from their last good backup, or moved to the
+corrupt directory there when no backup is available.`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+
+	cmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair corrupted records")
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	records, err := utils.CheckInstances()
+	if err != nil {
+		return fmt.Errorf("failed to check instance records: %w", err)
+	}
+
+	if len(records) == 0 {
+		fmt.Println(ui.SuccessStyle.Render("✅ All instance records are healthy\n"))
+		return nil
+	}
+
+	fmt.Println(ui.WarningStyle.Render(fmt.Sprintf("⚠️  Found %d corrupted instance records\n", len(records))))
+	for _, record := range records {
+		backup := "no backup"
+		if record.HasBackup {
+			backup = "backup available"
+		}
+		fmt.Printf("  • %s (%s)\n", record.Err, backup)
+	}
+	fmt.Println()
+
+	if !doctorFix {
+		fmt.Println(ui.InfoStyle.Render("💡 Repair: instant-db doctor --fix\n"))
+		return nil
+	}
+
+	for _, record := range records {
+		moved, err := utils.RepairInstance(record)
+		if err != nil {
+			return fmt.Errorf("failed to repair %s: %w", record.Path, err)
+		}
+		if moved != "" {
+			fmt.Println(ui.WarningStyle.Render(fmt.Sprintf("⚠️  Moved unrecoverable record to %s", moved)))
+		} else {
+			fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Restored %s from backup", record.Path)))
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
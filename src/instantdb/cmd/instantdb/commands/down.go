@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	downFile string
+	downStop bool
+)
+
+// DownCmd returns the down command
+func DownCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Pause or stop the instances of the project in instantdb.yaml",
+		Long: `Pause every instance of the project manifest (instantdb.yaml in the current
+directory or one of its parents), keeping their data for the next up. With --stop
+the instances are stopped and removed instead; data is kept only for instances
+declared with persist: true.`,
+		Args: cobra.NoArgs,
+		RunE: runDown,
+	}
+
+	cmd.Flags().StringVarP(&downFile, "file", "f", "", "Path to the manifest (default: closest instantdb.yaml)")
+	cmd.Flags().BoolVar(&downStop, "stop", false, "Stop and remove the instances instead of pausing them")
+
+	return cmd
+}
+
+func runDown(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	manifest, err := loadProjectManifest(downFile)
+	if err != nil {
+		return err
+	}
+
+	// Instances dropped from the manifest since the last up are included
+	instances, err := utils.ListProjectInstances(manifest.Dir)
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 {
+		fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("💡 No instances of %s. Start them: instant-db up\n", manifest.Project)))
+		return nil
+	}
+
+	byName := make(map[string]*types.Instance)
+	names := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		byName[instance.Name] = instance
+		names = append(names, instance.Name)
+	}
+
+	verb := "Pausing"
+	if downStop {
+		verb = "Stopping"
+	}
+
+	var results []projectResult
+	err = ui.ShowSpinner(fmt.Sprintf("%s %d instances of %s", verb, len(names), manifest.Project), func() error {
+		results = forEachParallel(ctx, names, func(ctx context.Context, name string) projectResult {
+			return downInstance(ctx, byName[name])
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return printProjectResults(results)
+}
+
+// downInstance pauses or stops a single project instance
+func downInstance(ctx context.Context, instance *types.Instance) projectResult {
+	result := projectResult{name: instance.Name}
+
+	engine, err := GetEngine(instance.Engine)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	if downStop {
+		if err := engine.Stop(ctx, instance.ID); err != nil {
+			result.err = fmt.Errorf("failed to stop: %w", err)
+			return result
+		}
+		result.action = "stopped"
+		return result
+	}
+
+	if instance.Paused {
+		result.action = "already paused"
+		return result
+	}
+	if err := engine.Pause(ctx, instance.ID); err != nil {
+		result.err = fmt.Errorf("failed to pause: %w", err)
+		return result
+	}
+	result.action = "paused"
+	return result
+}
@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dumpOutput string
+	dumpFormat string
+)
+
+// dumpProgressInterval limits how often the spinner shows dump progress
+const dumpProgressInterval = 200 * time.Millisecond
+
+// DumpCmd returns the dump command
+func DumpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dump <instance-name-or-id>",
+		Short: "Write a portable logical dump of an instance",
+		Long: `Write the contents of a running instance to a file that instant-db restore, or
+the engine's own tools, can load into any instance of the same engine.
+
+PostgreSQL and MySQL are dumped as SQL: the postgres database for PostgreSQL
+(databases with partitioned tables are refused), all user databases for MySQL
+(without stored routines, triggers and events).
+Redis is dumped as an RDB snapshot, or as a JSON array of keys with --format json
+or a .json file.
+
+The format follows the file extension (.sql, .json, .rdb) unless --format is
+given. Files ending in .gz are gzip compressed.`,
+		Example: `  instant-db dump shop -o shop.sql.gz
+  instant-db dump cache -o cache.json
+  instant-db dump shop > shop.sql`,
+		Args: cobra.ExactArgs(1),
+		RunE: runDump,
+	}
+
+	cmd.Flags().StringVarP(&dumpOutput, "output", "o", "", "File to write (default: standard output)")
+	cmd.Flags().StringVar(&dumpFormat, "format", "", "Dump format: sql, json or rdb (default: from the file extension)")
+
+	return cmd
+}
+
+func runDump(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	instance, engine, err := loadInstanceAndEngine(args[0])
+	if err != nil {
+		return err
+	}
+	dumper, err := instanceDumper(instance)
+	if err != nil {
+		return err
+	}
+	format, err := dumpFileFormat(dumper, dumpOutput, dumpFormat)
+	if err != nil {
+		return err
+	}
+	if err := requireRunning(ctx, engine, instance); err != nil {
+		return err
+	}
+
+	// Without a file the dump goes to standard output, which the spinner would garble
+	if dumpOutput == "" {
+		return dumper.Dump(ctx, instance.ID, format, os.Stdout)
+	}
+
+	var size int64
+	err = ui.ShowProgressSpinner(fmt.Sprintf("Dumping %s to %s", instance.Name, dumpOutput), func(progress func(string)) error {
+		var err error
+		size, err = writeDump(ctx, dumper, instance, format, dumpOutput, progress)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to dump instance: %w", err)
+	}
+
+	fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Dumped %s to %s (%s)\n", instance.Name, dumpOutput, ui.FormatBytes(size))))
+	fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("💡 Load it: instant-db restore %s %s\n", instance.Name, dumpOutput)))
+	return nil
+}
+
+// writeDump dumps an instance into a file, compressed if its name ends in .gz,
+// and returns the size of the file. A failed dump leaves no file behind.
+func writeDump(ctx context.Context, dumper engines.Dumper, instance *types.Instance, format, path string, progress func(string)) (int64, error) {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(tmp)
+
+	counter := &progressWriter{w: file, report: func(n int64) {
+		progress(ui.FormatBytes(n) + " written")
+	}}
+	var w io.Writer = counter
+	var compressor *gzip.Writer
+	if isGzipFile(path) {
+		compressor = gzip.NewWriter(counter)
+		w = compressor
+	}
+
+	err = dumper.Dump(ctx, instance.ID, format, w)
+	if err == nil && compressor != nil {
+		err = compressor.Close()
+	}
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", path, closeErr)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return counter.n, nil
+}
+
+// instanceDumper returns the dump support of an instance's engine
+func instanceDumper(instance *types.Instance) (engines.Dumper, error) {
+	local, err := localEngine(instance.Engine)
+	if err != nil {
+		return nil, err
+	}
+	dumper, ok := local.(engines.Dumper)
+	if !ok {
+		return nil, fmt.Errorf("%s instances cannot be dumped or restored", instance.Engine)
+	}
+	return dumper, nil
+}
+
+// dumpFileFormat picks the format of a dump file: the requested one, else the
+// one its extension names, else the engine's first format
+func dumpFileFormat(dumper engines.Dumper, path, requested string) (string, error) {
+	formats := dumper.DumpFormats()
+
+	format := requested
+	if format == "" {
+		ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(strings.ToLower(path), ".gz")))
+		format = strings.TrimPrefix(ext, ".")
+	}
+	if format == "" || (requested == "" && !isDumpFormat(format)) {
+		return formats[0], nil
+	}
+
+	for _, supported := range formats {
+		if format == supported {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported dump format %s; use one of: %s", format, strings.Join(formats, ", "))
+}
+
+// isDumpFormat reports whether a file extension names a dump format
+func isDumpFormat(format string) bool {
+	switch format {
+	case engines.DumpFormatSQL, engines.DumpFormatJSON, engines.DumpFormatRDB:
+		return true
+	}
+	return false
+}
+
+// isGzipFile reports whether a dump file is gzip compressed
+func isGzipFile(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), ".gz")
+}
+
+// requireRunning refuses instances that cannot take queries
+func requireRunning(ctx context.Context, engine engines.Engine, instance *types.Instance) error {
+	status, err := engine.Status(ctx, instance.ID)
+	if err != nil {
+		return err
+	}
+	switch status.State {
+	case types.StateRunning:
+		return nil
+	case types.StatePaused:
+		return fmt.Errorf("%s is paused; start it with instant-db resume %s", instance.Name, instance.Name)
+	}
+	return fmt.Errorf("%s is %s (%s); check it with instant-db prune --dry-run", instance.Name, status.State, status.Message)
+}
+
+// progressWriter counts the bytes written through it and reports the count
+// at most every dumpProgressInterval
+type progressWriter struct {
+	w        io.Writer
+	n        int64
+	report   func(n int64)
+	reported time.Time
+}
+
+func (p *progressWriter) Write(data []byte) (int, error) {
+	n, err := p.w.Write(data)
+	p.n += int64(n)
+	if time.Since(p.reported) >= dumpProgressInterval {
+		p.reported = time.Now()
+		p.report(p.n)
+	}
+	return n, err
+}
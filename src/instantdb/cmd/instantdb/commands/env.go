@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envFormat string
+	envPrefix string
+	envWrite  string
+)
+
+// EnvCmd returns the env command
+func EnvCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env <instance-name-or-id>[=PREFIX]...",
+		Short: "Print connection environment variables for instances",
+		Long: `Print the connection URL and the discrete host, port, user, password and database
+of instances as environment variables.
+
+Variables use the engine's conventional names (DATABASE_URL and PGHOST... for
+postgres, DATABASE_URL and MYSQL_HOST... for mysql, REDIS_URL and REDIS_HOST...
+for redis). --prefix APP_ yields APP_URL, APP_HOST... instead; name=PREFIX sets
+the prefix of a single instance.`,
+		Example: `  eval "$(instant-db env myapp)"
+  instant-db env shop/db shop/cache --write .env
+  instant-db env primary=PRIMARY_ replica=REPLICA_ --format json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runEnv,
+	}
+
+	cmd.Flags().StringVar(&envFormat, "format", "shell", "Output format (shell, dotenv, json)")
+	cmd.Flags().StringVar(&envPrefix, "prefix", "", "Prefix for all variable names instead of the engine's conventional names")
+	cmd.Flags().StringVar(&envWrite, "write", "", "Merge the variables into a dotenv file instead of printing them")
+
+	return cmd
+}
+
+func runEnv(cmd *cobra.Command, args []string) error {
+	if envFormat != "shell" && envFormat != "dotenv" && envFormat != "json" {
+		return fmt.Errorf("invalid format %q (use shell, dotenv or json)", envFormat)
+	}
+
+	var vars []utils.EnvVar
+	owners := make(map[string]string)
+
+	for _, arg := range args {
+		name, prefix, _ := strings.Cut(arg, "=")
+		if prefix == "" {
+			prefix = envPrefix
+		}
+
+		instanceVars, err := instanceEnv(name, prefix)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range instanceVars {
+			if owner, exists := owners[v.Key]; exists {
+				return fmt.Errorf("%s and %s would both set %s; give one a prefix with %s=PREFIX_", owner, name, v.Key, name)
+			}
+			owners[v.Key] = name
+		}
+		vars = append(vars, instanceVars...)
+	}
+
+	if envWrite != "" {
+		if err := utils.MergeDotenv(envWrite, vars); err != nil {
+			return err
+		}
+		fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Wrote %d variables to %s\n", len(vars), envWrite)))
+		return nil
+	}
+
+	switch envFormat {
+	case "json":
+		values := make(map[string]string, len(vars))
+		for _, v := range vars {
+			values[v.Key] = v.Value
+		}
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode variables: %w", err)
+		}
+		fmt.Println(string(data))
+	case "dotenv":
+		for _, v := range vars {
+			fmt.Println(utils.DotenvLine(v))
+		}
+	default:
+		for _, v := range vars {
+			fmt.Printf("export %s=%s\n", v.Key, shellQuote(v.Value))
+		}
+	}
+
+	return nil
+}
+
+// instanceEnv returns the connection variables of an instance, derived from its
+// connection URL. An empty prefix selects the engine's conventional names.
+func instanceEnv(nameOrID, prefix string) ([]utils.EnvVar, error) {
+	instanceID, err := resolveProjectInstance(nameOrID)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("instance not found: %w", err)
+	}
+
+	def, err := engines.Lookup(instance.Engine)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := GetEngine(def.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionURL, err := engine.GetConnectionURL(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection URL: %w", err)
+	}
+
+	urlName := def.URLEnvName()
+	if prefix == "" {
+		prefix = def.EnvPrefixName()
+	} else {
+		urlName = prefix + "URL"
+	}
+
+	vars := []utils.EnvVar{{Key: urlName, Value: connectionURL}}
+
+	parsed, err := url.Parse(connectionURL)
+	if err != nil || parsed.Host == "" {
+		return vars, nil
+	}
+	password, _ := parsed.User.Password()
+	discrete := []utils.EnvVar{
+		{Key: prefix + "HOST", Value: parsed.Hostname()},
+		{Key: prefix + "PORT", Value: parsed.Port()},
+		{Key: prefix + "USER", Value: parsed.User.Username()},
+		{Key: prefix + "PASSWORD", Value: password},
+		{Key: prefix + "DATABASE", Value: strings.TrimPrefix(parsed.Path, "/")},
+	}
+	for _, v := range discrete {
+		if v.Value != "" {
+			vars = append(vars, v)
+		}
+	}
+
+	return vars, nil
+}
+
+// shellQuote quotes a value for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
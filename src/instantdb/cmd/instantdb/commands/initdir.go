@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+)
+
+// checkInitScripts makes sure the engine can run every script before an instance is started
+func checkInitScripts(def *engines.Definition, scripts []string) error {
+	local, err := localEngine(def.Name)
+	if err != nil {
+		return err
+	}
+	return engines.CheckInitScripts(def, local, scripts)
+}
+
+// runInitScripts applies init scripts to a freshly started instance. Shell
+// scripts run with the instance's connection variables in their environment.
+func runInitScripts(ctx context.Context, def *engines.Definition, instance *types.Instance, scripts []string) error {
+	local, err := localEngine(def.Name)
+	if err != nil {
+		return err
+	}
+
+	// Shell scripts see the same variables as instant-db env prints
+	vars, err := instanceEnv(instance.ID, "")
+	if err != nil {
+		return err
+	}
+	env := os.Environ()
+	for _, v := range vars {
+		env = append(env, v.Key+"="+v.Value)
+	}
+
+	return engines.ApplyInitScripts(ctx, local, instance, scripts, env, func(script string) {
+		fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("📜 Running %s", filepath.Base(script))))
+	})
+}
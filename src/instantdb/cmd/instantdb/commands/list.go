@@ -1,9 +1,13 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -12,22 +16,33 @@ func ListCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
 		Short: "List all running instances",
-		Long:  `List all running PostgreSQL instances.`,
+		Long:  `List all instances and their live state (running, paused, crashed, port-taken or data-missing).`,
 		RunE:  runList,
 	}
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	// Get all instances from all engines
-	postgresInstances, _ := Engine.List()
-	mysqlInstances, _ := MySQLEngine.List()
-	redisInstances, _ := RedisEngine.List()
-	
-	// Combine all instances
-	allInstances := append(postgresInstances, mysqlInstances...)
-	allInstances = append(allInstances, redisInstances...)
-
-	fmt.Println(ui.RenderInstanceTable(allInstances))
+	ctx := context.Background()
+
+	// Get all instances from all engines, checking each one is really up
+	var allInstances []*types.Instance
+	statuses := make(map[string]*types.Status)
+	for _, def := range engines.Definitions() {
+		engine := loadedEngines[def.Name]
+		instances, _ := engine.List()
+		for _, instance := range instances {
+			if status, err := engine.Status(ctx, instance.ID); err == nil {
+				statuses[instance.ID] = status
+			}
+		}
+		allInstances = append(allInstances, instances...)
+	}
+
+	fmt.Println(ui.RenderInstanceTable(allInstances, statuses))
+
+	if corrupt, err := utils.CheckInstances(); err == nil && len(corrupt) > 0 {
+		fmt.Println(ui.WarningStyle.Render(fmt.Sprintf("⚠️  %d instance records are corrupted and not shown. Check: instant-db doctor\n", len(corrupt))))
+	}
 
 	return nil
 }
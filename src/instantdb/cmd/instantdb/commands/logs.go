@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsFollow bool
+	logsSince  time.Duration
+	logsTail   int
+)
+
+// logsPollInterval is how often a followed log is checked for new lines
+const logsPollInterval = 250 * time.Millisecond
+
+// LogsCmd returns the logs command
+func LogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs <instance-name-or-id>",
+		Short: "Show the server log of an instance",
+		Long: `Show the log the database server of an instance writes into its data directory:
+postgres.log, mysql.log or redis.log. Logs larger than 10 MB are rotated when
+the instance starts or resumes, and by the supervisor while it runs; the last
+three rotated logs are kept and shown before the current one.`,
+		Example: `  instant-db logs shop --tail 100
+  instant-db logs shop -f --since 10m`,
+		Args: cobra.ExactArgs(1),
+		RunE: runLogs,
+	}
+
+	cmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep printing new lines as they are logged")
+	cmd.Flags().DurationVar(&logsSince, "since", 0, "Only show lines logged within this duration, e.g. 10m")
+	cmd.Flags().IntVar(&logsTail, "tail", -1, "Number of lines to show from the end of the log (-1 for all)")
+
+	return cmd
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	instanceID, err := resolveProjectInstance(args[0])
+	if err != nil {
+		return err
+	}
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+	logFile, err := engines.InstanceLogFile(instance)
+	if err != nil {
+		return err
+	}
+
+	files := engines.LogFiles(logFile)
+	if len(files) == 0 && !logsFollow {
+		fmt.Println(ui.MutedStyle.Render(fmt.Sprintf("No logs for %s yet.\n", instance.Name)))
+		return nil
+	}
+
+	var cutoff time.Time
+	if logsSince > 0 {
+		cutoff = time.Now().Add(-logsSince)
+	}
+
+	// Lines are filtered by the time they were logged; lines without a time
+	// of their own belong to the message above them
+	var lines []string
+	var logged time.Time
+	var offset int64
+	for _, file := range files {
+		complete, partial, err := scanLogLines(file, 0, func(line string) {
+			if t, ok := engines.LogTime(line); ok {
+				logged = t
+			}
+			if !cutoff.IsZero() && logged.Before(cutoff) {
+				return
+			}
+			lines = append(lines, line)
+			if logsTail >= 0 && len(lines) > logsTail {
+				lines = lines[1:]
+			}
+		})
+		if err != nil {
+			return err
+		}
+		// A line still being written is left to follow mode
+		if file == logFile {
+			offset = complete
+			if partial != "" && !logsFollow {
+				lines = append(lines, partial)
+			}
+		}
+	}
+	if logsTail >= 0 && len(lines) > logsTail {
+		lines = lines[len(lines)-logsTail:]
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+	if err := out.Flush(); err != nil || !logsFollow {
+		return err
+	}
+
+	// Ctrl+C ends following quietly
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	restore := ui.HandleInterrupts(func(os.Signal) {
+		cancel()
+	})
+	defer restore()
+
+	return followLog(ctx, logFile, offset)
+}
+
+// scanLogLines passes the complete lines of a log after offset to fn and
+// returns the offset after the last of them and any unterminated last line
+func scanLogLines(path string, offset int64, fn func(line string)) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return offset, "", nil
+		}
+		return offset, "", fmt.Errorf("failed to read log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, "", fmt.Errorf("failed to read log: %w", err)
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return offset, line, nil
+		}
+		if err != nil {
+			return offset, "", fmt.Errorf("failed to read log: %w", err)
+		}
+		offset += int64(len(line))
+		fn(strings.TrimRight(line, "\r\n"))
+	}
+}
+
+// followLog prints the lines appended to a log after offset until ctx is done.
+// A log that shrank was rotated, and is followed again from its start.
+func followLog(ctx context.Context, path string, offset int64) error {
+	ticker := time.NewTicker(logsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.Size() < offset {
+			offset = 0
+		}
+		if info.Size() == offset {
+			continue
+		}
+
+		offset, _, err = scanLogLines(path, offset, func(line string) {
+			fmt.Println(line)
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// MigrateCmd returns the migrate command
+func MigrateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade stored instance records to the current schema",
+		Long: `Rewrite every instance record in the instant-db home directory in the current metadata schema.
+Older records are also upgraded in memory whenever they are read, so this is only
+needed to update the files themselves.`,
+		Args: cobra.NoArgs,
+		RunE: runMigrate,
+	}
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	upgraded, err := utils.MigrateInstances()
+	if err != nil {
+		return fmt.Errorf("failed to migrate instance records: %w", err)
+	}
+
+	if upgraded == 0 {
+		fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ All instance records are at schema version %d\n", utils.CurrentSchemaVersion)))
+		return nil
+	}
+
+	fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Upgraded %d instance records to schema version %d\n", upgraded, utils.CurrentSchemaVersion)))
+
+	return nil
+}
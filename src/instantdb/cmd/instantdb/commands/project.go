@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// projectResult is the outcome of up or down for one instance of a project
+type projectResult struct {
+	name   string
+	action string
+	url    string
+	err    error
+}
+
+// loadProjectManifest reads the manifest at path, or the closest instantdb.yaml
+// when path is empty
+func loadProjectManifest(path string) (*types.Manifest, error) {
+	if path == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+		path, err = utils.FindManifest(cwd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return utils.LoadManifest(path)
+}
+
+// findInstanceByName returns the instance with an exact name, or nil
+func findInstanceByName(name string) (*types.Instance, error) {
+	instances, err := utils.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+	for _, instance := range instances {
+		if instance.Name == name {
+			return instance, nil
+		}
+	}
+	return nil, nil
+}
+
+// forEachParallel runs fn for every name concurrently and returns the results in order
+func forEachParallel(ctx context.Context, names []string, fn func(ctx context.Context, name string) projectResult) []projectResult {
+	results := make([]projectResult, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = fn(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// printProjectResults renders the outcome of up or down and reports whether all succeeded
+func printProjectResults(results []projectResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+			fmt.Println(ui.ErrorStyle.Render(fmt.Sprintf("❌ %s: %v", result.name, result.err)))
+			printLogTail(result.err)
+			continue
+		}
+
+		fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ %s %s", result.name, result.action)))
+		if result.url != "" {
+			fmt.Println(ui.MutedStyle.Render("   " + result.url))
+		}
+	}
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d instances failed", failed, len(results))
+	}
+	return nil
+}
+
+// sortedInstanceNames returns the instance names of a manifest in a stable order
+func sortedInstanceNames(manifest *types.Manifest) []string {
+	names := make([]string, 0, len(manifest.Instances))
+	for name := range manifest.Instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
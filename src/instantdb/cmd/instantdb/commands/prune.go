@@ -0,0 +1,291 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/supervisor"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// staleStartAfter is how long an instance may stay in the starting state
+// before prune treats its start as interrupted
+const staleStartAfter = 10 * time.Minute
+
+// pruneStopTimeout is how long an untracked process gets to exit before it is killed
+const pruneStopTimeout = 10 * time.Second
+
+var (
+	pruneDryRun bool
+	pruneYes    bool
+)
+
+// pruneAction is a single change proposed by prune
+type pruneAction struct {
+	description string
+	apply       func() error
+}
+
+// PruneCmd returns the prune command
+func PruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Clean up stale instances, orphaned data and stray processes",
+		Long: `Cross-check saved instances against their data directories and running processes.
+
+Metadata of instances whose data directory is gone is dropped, instances whose
+process is gone are removed (or marked paused when started with --persist), data
+directories without an instance are deleted and untracked engine processes
+listening on recorded ports or using orphaned data are stopped.`,
+		Args: cobra.NoArgs,
+		RunE: runPrune,
+	}
+
+	cmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be cleaned up without changing anything")
+	cmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "Apply changes without asking for confirmation")
+
+	return cmd
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var actions []pruneAction
+	err := ui.ShowSpinner("Checking instances", func() error {
+		var err error
+		actions, err = planPrune(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check instances: %w", err)
+	}
+
+	if len(actions) == 0 {
+		fmt.Println(ui.SuccessStyle.Render("✅ Nothing to prune\n"))
+		return nil
+	}
+
+	fmt.Println(ui.TitleStyle.Render(fmt.Sprintf("🧹 Prune plan (%d)", len(actions))) + "\n")
+	for _, action := range actions {
+		fmt.Printf("  • %s\n", action.description)
+	}
+	fmt.Println()
+
+	if pruneDryRun {
+		fmt.Println(ui.InfoStyle.Render("💡 Dry run, nothing was changed. Apply: instant-db prune\n"))
+		return nil
+	}
+
+	if !pruneYes && ui.PromptSelect("Apply these changes?", []string{"Yes", "No"}) != "Yes" {
+		fmt.Println(ui.MutedStyle.Render("Nothing was changed\n"))
+		return nil
+	}
+
+	failed := 0
+	for _, action := range actions {
+		if err := action.apply(); err != nil {
+			failed++
+			fmt.Println(ui.ErrorStyle.Render(fmt.Sprintf("❌ %s: %v", action.description, err)))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d changes failed", failed, len(actions))
+	}
+
+	fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Applied %d changes\n", len(actions))))
+
+	return nil
+}
+
+// planPrune compares saved instances with data directories and running processes
+func planPrune(ctx context.Context) ([]pruneAction, error) {
+	// Data of a corrupted record would look orphaned, so repair comes first
+	corrupt, err := utils.CheckInstances()
+	if err != nil {
+		return nil, err
+	}
+	if len(corrupt) > 0 {
+		return nil, fmt.Errorf("found %d corrupted instance records, repair them first: instant-db doctor --fix", len(corrupt))
+	}
+
+	instances, err := utils.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	supervised := supervisor.Detect() != nil
+	referenced := make(map[string]bool)
+	var actions []pruneAction
+
+	for _, instance := range instances {
+		referenced[filepath.Clean(instance.DataDir)] = true
+
+		// Instances of engine plugins that are no longer installed are left alone
+		engine, err := localEngine(instance.Engine)
+		if err != nil {
+			continue
+		}
+
+		status, err := engine.Status(ctx, instance.ID)
+		if err != nil {
+			continue
+		}
+
+		switch status.State {
+		case types.StateStarting:
+			if time.Since(time.Unix(instance.CreatedAt, 0)) < staleStartAfter {
+				continue
+			}
+			actions = append(actions, pruneAction{
+				description: fmt.Sprintf("remove %s (%s) and its data: start was interrupted", instance.Name, instance.ID),
+				apply: func() error {
+					return removeInstanceAndData(instance)
+				},
+			})
+		case types.StateDataMissing:
+			actions = append(actions, pruneAction{
+				description: fmt.Sprintf("drop metadata of %s (%s): %s", instance.Name, instance.ID, status.Message),
+				apply: func() error {
+					return utils.RemoveInstance(instance.ID)
+				},
+			})
+		case types.StatePortTaken:
+			if action, ok := planKillOnPort(instance); ok {
+				actions = append(actions, action)
+			}
+			fallthrough
+		case types.StateCrashed:
+			// The supervisor restarts these on its own
+			if supervised && instance.RestartPolicy != "" && instance.RestartPolicy != supervisor.RestartNo {
+				continue
+			}
+			actions = append(actions, planStaleInstance(instance, status))
+		}
+	}
+
+	orphans, err := planOrphanedData(referenced)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(actions, orphans...), nil
+}
+
+// planStaleInstance cleans up an instance whose process is gone the way stop or pause would
+func planStaleInstance(instance *types.Instance, status *types.Status) pruneAction {
+	if instance.Persist {
+		return pruneAction{
+			description: fmt.Sprintf("mark %s (%s) as paused, keeping its data: %s", instance.Name, instance.ID, status.Message),
+			apply: func() error {
+				return utils.UpdateInstance(instance.ID, func(instance *types.Instance) error {
+					instance.Paused = true
+					instance.Status = types.StatePaused
+					instance.PID = 0
+					instance.PGID = 0
+					return nil
+				})
+			},
+		}
+	}
+
+	return pruneAction{
+		description: fmt.Sprintf("remove %s (%s) and its data: %s", instance.Name, instance.ID, status.Message),
+		apply: func() error {
+			return removeInstanceAndData(instance)
+		},
+	}
+}
+
+// removeInstanceAndData deletes an instance's data directory and metadata
+func removeInstanceAndData(instance *types.Instance) error {
+	if err := os.RemoveAll(instance.DataDir); err != nil {
+		return fmt.Errorf("failed to remove data directory: %w", err)
+	}
+	return utils.RemoveInstance(instance.ID)
+}
+
+// planKillOnPort stops an untracked engine process holding an instance's port.
+// Processes that do not belong to a known engine are never touched.
+func planKillOnPort(instance *types.Instance) (pruneAction, bool) {
+	pid, err := utils.FindProcessOnPort(instance.Port)
+	if err != nil || pid == 0 || pid == instance.PID {
+		return pruneAction{}, false
+	}
+
+	name, err := utils.ProcessName(pid)
+	if err != nil || !isEngineProcess(name) {
+		return pruneAction{}, false
+	}
+
+	return pruneAction{
+		description: fmt.Sprintf("stop untracked %s process %d listening on port %d of %s", name, pid, instance.Port, instance.Name),
+		apply: func() error {
+			return utils.TerminateProcess(pid, utils.ProcessGroup(pid), pruneStopTimeout)
+		},
+	}, true
+}
+
+// planOrphanedData removes data directories that no instance refers to,
+// stopping engine processes still using them first
+func planOrphanedData(referenced map[string]bool) ([]pruneAction, error) {
+	entries, err := os.ReadDir(baseDataDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data directory: %w", err)
+	}
+
+	var actions []pruneAction
+	for _, entry := range entries {
+		dir := filepath.Join(baseDataDir, entry.Name())
+		if !entry.IsDir() || referenced[dir] {
+			continue
+		}
+
+		pids, err := utils.FindProcessesUsing(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, pid := range pids {
+			name, err := utils.ProcessName(pid)
+			if err != nil || !isEngineProcess(name) {
+				continue
+			}
+			actions = append(actions, pruneAction{
+				description: fmt.Sprintf("stop untracked %s process %d using %s", name, pid, dir),
+				apply: func() error {
+					return utils.TerminateProcess(pid, utils.ProcessGroup(pid), pruneStopTimeout)
+				},
+			})
+		}
+
+		actions = append(actions, pruneAction{
+			description: fmt.Sprintf("delete orphaned data directory %s", dir),
+			apply: func() error {
+				return os.RemoveAll(dir)
+			},
+		})
+	}
+
+	return actions, nil
+}
+
+// isEngineProcess reports whether name is the server process of a registered engine
+func isEngineProcess(name string) bool {
+	for _, def := range engines.Definitions() {
+		for _, processName := range def.ProcessNames {
+			if name == processName {
+				return true
+			}
+		}
+	}
+	return false
+}
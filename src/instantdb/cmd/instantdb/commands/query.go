@@ -0,0 +1,453 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryCommand string
+	queryFormat  string
+)
+
+// queryHistorySize is the number of lines kept in a query history file
+const queryHistorySize = 500
+
+const queryHelp = `  \dt [pattern]     list tables, or keys, matching a pattern such as user*
+  \d <name>         describe a table or key
+  \timing [on|off]  show how long statements take
+  \?                show this help
+  \q                quit
+
+SQL statements end with a semicolon and may span several lines.
+Ctrl+C discards the current input or cancels a running statement.`
+
+// QueryCmd returns the query command
+func QueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query <instance-name-or-id>",
+		Short: "Run queries on an instance without installing a client",
+		Long: `Open a prompt that runs SQL statements or redis commands on a running instance
+through instant-db's own drivers, with no psql, mysql or redis-cli needed.
+Statements may span several lines; up and down walk through the history.
+Type \? for the commands that list and describe tables or keys.
+
+When input is piped in, or given with -c, the statements are run in order and
+the first failure ends the command. Results are printed as a table on a
+terminal and as CSV otherwise; --format picks table, csv or json.`,
+		Example: `  instant-db query shop
+  instant-db query shop -c "SELECT * FROM orders LIMIT 5"
+  instant-db query shop --format json < report.sql
+  instant-db query cache -c "HGETALL session:42"`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          runQuery,
+	}
+
+	cmd.Flags().StringVarP(&queryCommand, "command", "c", "", "Run these statements and exit")
+	cmd.Flags().StringVar(&queryFormat, "format", "", "Output format: table, csv or json (default: table on a terminal, csv otherwise)")
+
+	return cmd
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	format := queryFormat
+	if format == "" {
+		format = "csv"
+		if isTerminal(os.Stdout) {
+			format = "table"
+		}
+	}
+	if format != "table" && format != "csv" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected table, csv or json", format)
+	}
+
+	instanceID, err := resolveProjectInstance(args[0])
+	if err != nil {
+		return err
+	}
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+	engine, err := GetEngine(instance.Engine)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if err := requireRunning(ctx, engine, instance); err != nil {
+		return err
+	}
+
+	local, err := localEngine(instance.Engine)
+	if err != nil {
+		return err
+	}
+	querier, ok := local.(engines.Querier)
+	if !ok {
+		return fmt.Errorf("%s instances cannot be queried", instance.Engine)
+	}
+	session, err := querier.OpenQuery(ctx, instance.ID)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	interactive := queryCommand == "" && isTerminal(os.Stdin)
+	q := &queryRunner{
+		session: session,
+		out:     bufio.NewWriter(os.Stdout),
+		format:  format,
+		timing:  interactive,
+	}
+	defer q.out.Flush()
+
+	// Ctrl+C cancels the running statement; at the prompt it is a key press
+	restore := ui.HandleInterrupts(func(os.Signal) {
+		q.cancelStatement()
+	})
+	defer restore()
+
+	if interactive {
+		return q.interactive(instance)
+	}
+
+	input := queryCommand
+	if input == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		input = string(data)
+	}
+	if err := q.script(input); err != nil {
+		q.out.Flush()
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("❌ %v", err)))
+		return &ExitCodeError{Code: 1}
+	}
+	return nil
+}
+
+// queryRunner runs statements and meta commands and prints their results
+type queryRunner struct {
+	session engines.QuerySession
+	out     *bufio.Writer
+	format  string
+	timing  bool
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// interactive reads statements from the terminal until \q or Ctrl+D
+func (q *queryRunner) interactive(instance *types.Instance) error {
+	history, historyFile := loadQueryHistory(instance.Engine)
+	if historyFile != nil {
+		defer historyFile.Close()
+	}
+
+	fmt.Println(ui.MutedStyle.Render(fmt.Sprintf("Connected to %s (%s). Type \\? for help, \\q to quit.\n", instance.Name, instance.Engine)))
+
+	var buffer string
+	for {
+		prompt := instance.Name + "=> "
+		if buffer != "" {
+			prompt = instance.Name + "-> "
+		}
+		line, err := ui.ReadLine(prompt, history)
+		if errors.Is(err, ui.ErrLineCanceled) {
+			buffer = ""
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if strings.TrimSpace(line) == "" && buffer == "" {
+			continue
+		}
+		if strings.TrimSpace(line) != "" && (len(history) == 0 || history[len(history)-1] != line) {
+			history = append(history, line)
+			if historyFile != nil {
+				fmt.Fprintln(historyFile, line)
+			}
+		}
+
+		if buffer == "" && strings.HasPrefix(strings.TrimSpace(line), `\`) {
+			quit, err := q.meta(strings.TrimSpace(line))
+			if err != nil {
+				q.printError(err)
+			}
+			q.out.Flush()
+			if quit {
+				return nil
+			}
+			continue
+		}
+
+		buffer += line + "\n"
+		statements, complete := q.session.Split(buffer, false)
+		if !complete {
+			continue
+		}
+		buffer = ""
+		for _, statement := range statements {
+			if err := q.run(statement); err != nil {
+				q.printError(err)
+				break
+			}
+		}
+		q.out.Flush()
+	}
+}
+
+// script runs every statement and meta command of input, stopping at the first error
+func (q *queryRunner) script(input string) error {
+	var buffer string
+	lines := strings.Split(input, "\n")
+	for i, line := range lines {
+		if buffer == "" && strings.HasPrefix(strings.TrimSpace(line), `\`) {
+			quit, err := q.meta(strings.TrimSpace(line))
+			if err != nil || quit {
+				return err
+			}
+			continue
+		}
+
+		buffer += line + "\n"
+		statements, complete := q.session.Split(buffer, i == len(lines)-1)
+		if !complete {
+			continue
+		}
+		buffer = ""
+		for _, statement := range statements {
+			if err := q.run(statement); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// meta runs a backslash command and reports whether it asked to quit
+func (q *queryRunner) meta(line string) (bool, error) {
+	fields := strings.Fields(line)
+	arg := ""
+	if len(fields) > 1 {
+		arg = fields[1]
+	}
+
+	switch fields[0] {
+	case `\q`, `\quit`:
+		return true, nil
+	case `\?`, `\h`, `\help`:
+		fmt.Fprintln(q.out, queryHelp)
+	case `\dt`:
+		return false, q.show(func(ctx context.Context) (*engines.QueryResult, error) {
+			return q.session.Tables(ctx, arg)
+		})
+	case `\d`:
+		if arg == "" {
+			return false, q.show(func(ctx context.Context) (*engines.QueryResult, error) {
+				return q.session.Tables(ctx, "")
+			})
+		}
+		return false, q.show(func(ctx context.Context) (*engines.QueryResult, error) {
+			return q.session.Describe(ctx, arg)
+		})
+	case `\timing`:
+		switch arg {
+		case "":
+			q.timing = !q.timing
+		case "on":
+			q.timing = true
+		case "off":
+			q.timing = false
+		default:
+			return false, fmt.Errorf(`\timing expects on or off, got %q`, arg)
+		}
+		if q.format == "table" {
+			state := "off"
+			if q.timing {
+				state = "on"
+			}
+			fmt.Fprintln(q.out, ui.MutedStyle.Render("Timing is "+state+"."))
+		}
+	default:
+		return false, fmt.Errorf(`unknown command %s, type \? for help`, fields[0])
+	}
+	return false, nil
+}
+
+// run runs a statement and prints its result
+func (q *queryRunner) run(statement string) error {
+	return q.show(func(ctx context.Context) (*engines.QueryResult, error) {
+		return q.session.Run(ctx, statement)
+	})
+}
+
+// show runs fn with a context Ctrl+C cancels and prints its result
+func (q *queryRunner) show(fn func(ctx context.Context) (*engines.QueryResult, error)) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	q.mu.Lock()
+	q.cancel = cancel
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		q.cancel = nil
+		q.mu.Unlock()
+		cancel()
+	}()
+
+	started := time.Now()
+	result, err := fn(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("canceled")
+		}
+		return err
+	}
+	return q.print(result, time.Since(started))
+}
+
+// cancelStatement cancels the statement being run, if any
+func (q *queryRunner) cancelStatement() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.cancel != nil {
+		q.cancel()
+	}
+}
+
+// print writes a result in the output format. Only tables carry the status
+// and timing, so CSV and JSON output holds nothing but rows.
+func (q *queryRunner) print(result *engines.QueryResult, elapsed time.Duration) error {
+	switch q.format {
+	case "csv":
+		if len(result.Columns) == 0 {
+			return nil
+		}
+		w := csv.NewWriter(q.out)
+		w.Write(result.Columns)
+		for _, row := range result.Rows {
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = value.String
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+	case "json":
+		if len(result.Columns) == 0 {
+			return nil
+		}
+		return writeQueryJSON(q.out, result)
+	}
+
+	if len(result.Columns) > 0 {
+		fmt.Fprint(q.out, ui.RenderResultTable(result.Columns, result.Rows))
+	}
+	fmt.Fprintln(q.out, ui.MutedStyle.Render("("+result.Status+")"))
+	if q.timing {
+		fmt.Fprintln(q.out, ui.MutedStyle.Render(fmt.Sprintf("Time: %.3f ms", float64(elapsed.Microseconds())/1000)))
+	}
+	fmt.Fprintln(q.out)
+	return nil
+}
+
+// printError reports a failed statement and carries on with the prompt
+func (q *queryRunner) printError(err error) {
+	q.out.Flush()
+	fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("❌ %v", err))+"\n")
+}
+
+// writeQueryJSON writes the rows of a result as a JSON array of objects,
+// keeping the order of the columns
+func writeQueryJSON(w io.Writer, result *engines.QueryResult) error {
+	var b strings.Builder
+	b.WriteString("[")
+	for r, row := range result.Rows {
+		if r > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("{")
+		for i, value := range row {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			name, _ := json.Marshal(result.Columns[i])
+			b.Write(name)
+			b.WriteString(":")
+			b.WriteString(queryJSONValue(value))
+		}
+		b.WriteString("}")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// queryJSONValue encodes a value as a JSON string, or null for NULL
+func queryJSONValue(value sql.NullString) string {
+	if !value.Valid {
+		return "null"
+	}
+	data, _ := json.Marshal(value.String)
+	return string(data)
+}
+
+// loadQueryHistory returns the saved prompt history of an engine, oldest
+// first, and the history file opened for appending new lines
+func loadQueryHistory(engine string) ([]string, *os.File) {
+	home, err := utils.HomeDir()
+	if err != nil {
+		return nil, nil
+	}
+	path := filepath.Join(home, "history", engine)
+
+	var history []string
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+		history = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+	if len(history) > queryHistorySize {
+		history = history[len(history)-queryHistorySize:]
+		os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0600)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return history, nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return history, nil
+	}
+	return history, file
+}
+
+// isTerminal reports whether a file is an interactive terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var restoreFormat string
+
+// RestoreCmd returns the restore command
+func RestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <instance-name-or-id> <file>",
+		Short: "Load a dump written by instant-db dump into an instance",
+		Long: `Load a dump into an instance of the same engine. SQL and JSON dumps are loaded
+into the running instance, on top of its existing data. An RDB snapshot replaces
+the data of a redis instance, which is paused while the snapshot is put in place.
+
+The format follows the file extension (.sql, .json, .rdb) unless --format is
+given. Files ending in .gz are decompressed.`,
+		Example: `  instant-db restore shop shop.sql.gz
+  instant-db restore cache cache.rdb`,
+		Args: cobra.ExactArgs(2),
+		RunE: runRestore,
+	}
+
+	cmd.Flags().StringVar(&restoreFormat, "format", "", "Dump format: sql, json or rdb (default: from the file extension)")
+
+	return cmd
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	path := args[1]
+
+	instance, engine, err := loadInstanceAndEngine(args[0])
+	if err != nil {
+		return err
+	}
+	dumper, err := instanceDumper(instance)
+	if err != nil {
+		return err
+	}
+	format, err := dumpFileFormat(dumper, path, restoreFormat)
+	if err != nil {
+		return err
+	}
+	offline := dumper.OfflineLoad(format)
+	if !offline {
+		if err := requireRunning(ctx, engine, instance); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open dump: %w", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if isGzipFile(path) {
+		decompressor, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		defer decompressor.Close()
+		r = decompressor
+	}
+
+	err = ui.ShowProgressSpinner(fmt.Sprintf("Restoring %s into %s", path, instance.Name), func(progress func(string)) error {
+		percent := -1
+		report := func(done, total int) {
+			if total > 0 && done*100/total != percent {
+				percent = done * 100 / total
+				progress(fmt.Sprintf("%d%%", percent))
+			}
+		}
+
+		if offline {
+			return engines.WithInstancePaused(ctx, engine, instance, func() error {
+				return dumper.Load(ctx, instance.ID, format, r, report)
+			})
+		}
+		return dumper.Load(ctx, instance.ID, format, r, report)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore dump: %w", err)
+	}
+
+	fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Restored %s into %s\n", path, instance.Name)))
+	return nil
+}
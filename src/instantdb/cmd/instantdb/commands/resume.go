@@ -38,6 +38,7 @@ func runResume(cmd *cobra.Command, args []string) error {
 	})
 
 	if err != nil {
+		printLogTail(err)
 		return fmt.Errorf("failed to resume instance: %w", err)
 	}
 
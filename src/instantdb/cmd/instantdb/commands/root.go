@@ -1,28 +1,122 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/supervisor"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
 	"github.com/spf13/cobra"
 )
 
-var (
-	Engine       engines.Engine
-	MySQLEngine  engines.Engine
-	RedisEngine  engines.Engine
-)
+// loadedEngines holds one engine per registered definition, keyed by canonical name
+var loadedEngines = make(map[string]engines.Engine)
+
+// baseDataDir is the directory holding instance data directories
+var baseDataDir string
+
+// rootHome is the value of the persistent --home flag
+var rootHome string
+
+// userConfig holds the defaults from config.yaml in the home directory
+var userConfig *types.UserConfig
+
+// discoverPlugins registers engine plugins found on PATH alongside the built-in engines
+func discoverPlugins() {
+	for _, err := range engines.DiscoverPlugins() {
+		fmt.Fprintln(os.Stderr, ui.WarningStyle.Render(fmt.Sprintf("⚠️  %v", err)))
+	}
+}
+
+// homeFromArgs returns the value of --home in args, before cobra parses them
+func homeFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--home="); ok {
+			return value
+		}
+		if arg == "--home" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// InitEngine initializes the registered database engines under the instant-db home directory
+func InitEngine() error {
+	return initEngine(true)
+}
+
+// initEngine initializes the engines. Without validateConfig, invalid values
+// in config.yaml are only warned about, so the config command can repair them.
+func initEngine(validateConfig bool) error {
+	if rootHome != "" {
+		utils.SetHomeDir(rootHome)
+	}
+
+	dataDir, err := utils.DataDir()
+	if err != nil {
+		return err
+	}
+	baseDataDir = dataDir
+
+	userConfig, err = utils.ReadUserConfig()
+	if err != nil {
+		return err
+	}
+	if err := utils.ValidateUserConfig(userConfig); err != nil {
+		if validateConfig {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, ui.WarningStyle.Render(fmt.Sprintf("⚠️  %v", err)))
+	}
+
+	for _, def := range engines.Definitions() {
+		engine := def.New(baseDataDir)
+		mirror := userConfig.Engines[def.Name].Mirror
+		if mirror == "" && def.ReleaseBinaries {
+			mirror = userConfig.Mirror
+		}
+		if mirror != "" {
+			if setter, ok := engine.(engines.MirrorSetter); ok {
+				setter.SetMirror(mirror)
+			}
+		}
+		loadedEngines[def.Name] = engine
+	}
+
+	return nil
+}
 
-// InitEngine initializes the database engines
-func InitEngine() {
-	homeDir, _ := os.UserHomeDir()
-	baseDir := homeDir + "/.instant-db/data"
-	
-	Engine = engines.NewPostgresEngine(baseDir)
-	MySQLEngine = engines.NewMySQLEngine(baseDir)
-	RedisEngine = engines.NewRedisEngine(baseDir)
+// GetEngine returns the engine registered under a name or alias.
+// When the supervisor is running, lifecycle calls are routed through it.
+func GetEngine(name string) (engines.Engine, error) {
+	def, err := engines.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := loadedEngines[def.Name]
+	if client := supervisor.Detect(); client != nil {
+		return supervisor.NewRemoteEngine(client, def.Name, engine), nil
+	}
+	return engine, nil
+}
+
+// localEngine returns the in-process engine registered under a name or alias
+func localEngine(name string) (engines.Engine, error) {
+	def, err := engines.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return loadedEngines[def.Name], nil
 }
 
 // GetEngineForInstance returns the appropriate engine for an instance
@@ -31,16 +125,15 @@ func GetEngineForInstance(instanceID string) (engines.Engine, error) {
 	if err != nil {
 		return nil, fmt.Errorf("instance not found: %w", err)
 	}
-	
-	switch instance.Engine {
-	case "mysql":
-		return MySQLEngine, nil
-	case "postgres":
-		return Engine, nil
-	case "redis":
-		return RedisEngine, nil
-	default:
-		return nil, fmt.Errorf("unknown engine: %s", instance.Engine)
+
+	return GetEngine(instance.Engine)
+}
+
+// printLogTail shows the engine log attached to a failed start or resume, if any
+func printLogTail(err error) {
+	var startErr *engines.StartError
+	if errors.As(err, &startErr) && startErr.LogTail != "" {
+		fmt.Fprintln(os.Stderr, ui.RenderLogTail(startErr.LogTail))
 	}
 }
 
@@ -51,10 +144,19 @@ func GetRootCommand(version string) *cobra.Command {
 		Short:   "Instant, isolated database instances for development",
 		Long:    `A CLI tool that spins up isolated database instances instantly for development, with zero configuration.`,
 		Version: version,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return InitEngine()
+		},
 	}
 
-	// Initialize engines
-	InitEngine()
+	rootCmd.PersistentFlags().StringVar(&rootHome, "home", "", fmt.Sprintf("instant-db home directory (default ~/.instant-db, or $%s)", utils.HomeEnv))
+
+	// Plugins are registered up front so their names show up in help output.
+	// Their info is cached in the home directory, so --home is applied first.
+	if home := homeFromArgs(os.Args[1:]); home != "" {
+		utils.SetHomeDir(home)
+	}
+	discoverPlugins()
 
 	// Add all commands
 	rootCmd.AddCommand(StartCmd())
@@ -62,8 +164,26 @@ func GetRootCommand(version string) *cobra.Command {
 	rootCmd.AddCommand(PauseCmd())
 	rootCmd.AddCommand(ResumeCmd())
 	rootCmd.AddCommand(ListCmd())
+	rootCmd.AddCommand(DashboardCmd())
 	rootCmd.AddCommand(URLCmd())
 	rootCmd.AddCommand(StatusCmd())
+	rootCmd.AddCommand(LogsCmd())
+	rootCmd.AddCommand(ShellCmd())
+	rootCmd.AddCommand(QueryCmd())
+	rootCmd.AddCommand(EnvCmd())
+	rootCmd.AddCommand(SnapshotCmd())
+	rootCmd.AddCommand(CloneCmd())
+	rootCmd.AddCommand(DumpCmd())
+	rootCmd.AddCommand(RestoreCmd())
+	rootCmd.AddCommand(SupervisorCmd())
+	rootCmd.AddCommand(PruneCmd())
+	rootCmd.AddCommand(DoctorCmd())
+	rootCmd.AddCommand(MigrateCmd())
+	rootCmd.AddCommand(ConfigCmd())
+	rootCmd.AddCommand(UpCmd())
+	rootCmd.AddCommand(DownCmd())
+	rootCmd.AddCommand(BranchCmd())
+	rootCmd.AddCommand(RunCmd())
 
 	return rootCmd
 }
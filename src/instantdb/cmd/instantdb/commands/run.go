@@ -0,0 +1,242 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/supervisor"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var runEngines []string
+
+// ExitCodeError makes the process exit with Code without printing anything
+type ExitCodeError struct {
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// runInstance is an instance started by run and the variable holding its URL
+type runInstance struct {
+	engine   engines.Engine
+	instance *types.Instance
+	envName  string
+}
+
+// RunCmd returns the run command
+func RunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run -e <engine>[=VAR] [-e ...] -- <command> [args...]",
+		Short: "Run a command with temporary database instances",
+		Long: `Start one instance per --engine, run the command with their connection URLs in
+its environment and remove the instances when it exits, is interrupted or fails.
+
+URLs are exported as DATABASE_URL for postgres and mysql, REDIS_URL for redis and
+<ENGINE>_URL for plugins. Choose another name with -e engine=VAR. The command's
+exit code is returned and signals are forwarded to it.`,
+		Example: `  instant-db run -e postgres -e redis -- go test ./...
+  instant-db run -e postgres=PRIMARY_URL -e postgres=REPLICA_URL -- ./integration.sh`,
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          runRun,
+	}
+
+	cmd.Flags().StringArrayVarP(&runEngines, "engine", "e", nil, "Engine to start, optionally with the variable for its URL (engine=VAR)")
+	cmd.MarkFlagRequired("engine")
+
+	// Everything after the command name belongs to the command
+	cmd.Flags().SetInterspersed(false)
+
+	return cmd
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	specs, err := parseRunEngines(runEngines)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Until the command runs, an interrupt aborts the starts in progress
+	var (
+		mu       sync.Mutex
+		child    *os.Process
+		received os.Signal
+	)
+	restore := ui.HandleInterrupts(func(sig os.Signal) {
+		mu.Lock()
+		defer mu.Unlock()
+		if received == nil {
+			received = sig
+		}
+		if child != nil {
+			forwardSignal(child, sig)
+			return
+		}
+		fmt.Fprintln(os.Stderr, ui.WarningStyle.Render("\n⚠️  Interrupt received, removing instances..."))
+		cancel()
+	})
+	defer restore()
+
+	var started []runInstance
+	defer func() {
+		teardownRunInstances(started)
+	}()
+
+	for _, spec := range specs {
+		fmt.Fprintln(os.Stderr, ui.InfoStyle.Render(fmt.Sprintf("🚀 Starting %s instance for %s...", spec.def.Name, spec.envName)))
+
+		instance, err := startRunInstance(ctx, spec)
+		if err != nil {
+			if ctx.Err() != nil {
+				return &ExitCodeError{Code: 130}
+			}
+			fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("❌ %v", err)))
+			printLogTail(err)
+			return &ExitCodeError{Code: 1}
+		}
+		started = append(started, *instance)
+	}
+
+	env := os.Environ()
+	for _, run := range started {
+		url, err := run.engine.GetConnectionURL(run.instance.ID)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("❌ failed to get connection URL: %v", err)))
+			return &ExitCodeError{Code: 1}
+		}
+		env = append(env, run.envName+"="+url)
+	}
+
+	command := exec.Command(args[0], args[1:]...)
+	command.Env = env
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	mu.Lock()
+	if received != nil {
+		mu.Unlock()
+		return &ExitCodeError{Code: 130}
+	}
+	err = command.Start()
+	if err == nil {
+		child = command.Process
+	}
+	mu.Unlock()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("❌ failed to run %s: %v", args[0], err)))
+		return &ExitCodeError{Code: 127}
+	}
+
+	err = command.Wait()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to wait for %s: %w", args[0], err)
+	}
+
+	return exitCode(command.ProcessState)
+}
+
+// runEngineSpec is a parsed --engine value
+type runEngineSpec struct {
+	def     *engines.Definition
+	envName string
+}
+
+// parseRunEngines resolves engine[=VAR] values and makes sure every URL gets its own variable
+func parseRunEngines(values []string) ([]runEngineSpec, error) {
+	var specs []runEngineSpec
+	used := make(map[string]string)
+
+	for _, value := range values {
+		name, envName, _ := strings.Cut(value, "=")
+		def, err := engines.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		if envName == "" {
+			envName = def.URLEnvName()
+		}
+
+		if previous, exists := used[envName]; exists {
+			return nil, fmt.Errorf("%s and %s would both set %s; name one of them with -e %s=OTHER_URL", previous, value, envName, def.Name)
+		}
+		used[envName] = value
+
+		specs = append(specs, runEngineSpec{def: def, envName: envName})
+	}
+
+	return specs, nil
+}
+
+// startRunInstance starts a throwaway instance with the configured defaults
+func startRunInstance(ctx context.Context, spec runEngineSpec) (*runInstance, error) {
+	engine, err := GetEngine(spec.def.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	username, password, version := engineDefaults(spec.def)
+	instance, err := engine.Start(ctx, types.Config{
+		Username: username,
+		Password: password,
+		Engine:   spec.def.Name,
+		Version:  version,
+
+		RestartPolicy: supervisor.RestartNo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s instance: %w", spec.def.Name, err)
+	}
+
+	return &runInstance{engine: engine, instance: instance, envName: spec.envName}, nil
+}
+
+// teardownRunInstances stops and removes the instances started by run
+func teardownRunInstances(started []runInstance) {
+	for _, run := range started {
+		if err := run.engine.Stop(context.Background(), run.instance.ID); err != nil {
+			fmt.Fprintln(os.Stderr, ui.ErrorStyle.Render(fmt.Sprintf("❌ failed to remove %s: %v", run.instance.Name, err)))
+			continue
+		}
+		fmt.Fprintln(os.Stderr, ui.MutedStyle.Render(fmt.Sprintf("🧹 Removed %s", run.instance.Name)))
+	}
+}
+
+// forwardSignal passes a signal on to the child, killing it where the
+// platform cannot deliver the signal
+func forwardSignal(process *os.Process, sig os.Signal) {
+	if err := process.Signal(sig); err != nil {
+		process.Kill()
+	}
+}
+
+// exitCode turns the state of the finished command into the error returned by
+// run, using 128+N for commands killed by signal N
+func exitCode(state *os.ProcessState) error {
+	code := state.ExitCode()
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		code = 128 + int(status.Signal())
+	}
+	if code == 0 {
+		return nil
+	}
+	return &ExitCodeError{Code: code}
+}
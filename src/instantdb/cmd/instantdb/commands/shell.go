@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var shellCommand string
+
+// ShellCmd returns the shell command
+func ShellCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shell <instance-name-or-id>",
+		Short: "Open the engine's command-line client on an instance",
+		Long: `Open psql, mysql or redis-cli connected to a running instance. The client
+bundled with the downloaded server binaries is used when there is one, and
+the one on PATH otherwise. Credentials are filled in without appearing on
+the command line.
+
+With -c the query is run and the client exits, with the client's exit code.`,
+		Example: `  instant-db shell shop
+  instant-db shell shop -c "SELECT count(*) FROM orders"
+  instant-db shell cache -c "INFO keyspace"`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          runShell,
+	}
+
+	cmd.Flags().StringVarP(&shellCommand, "command", "c", "", "Run a single query and exit")
+
+	return cmd
+}
+
+func runShell(cmd *cobra.Command, args []string) error {
+	instanceID, err := resolveProjectInstance(args[0])
+	if err != nil {
+		return err
+	}
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+	engine, err := GetEngine(instance.Engine)
+	if err != nil {
+		return err
+	}
+	if err := requireRunning(context.Background(), engine, instance); err != nil {
+		return err
+	}
+
+	local, err := localEngine(instance.Engine)
+	if err != nil {
+		return err
+	}
+	shell, ok := local.(engines.Shell)
+	if !ok {
+		return fmt.Errorf("%s instances have no shell", instance.Engine)
+	}
+
+	client, cleanup, err := shell.ShellCommand(instance, shellCommand)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	client.Stdin = os.Stdin
+	client.Stdout = os.Stdout
+	client.Stderr = os.Stderr
+
+	// Ctrl+C belongs to the client, which shares the terminal
+	restore := ui.HandleInterrupts(func(os.Signal) {})
+	defer restore()
+
+	if err := client.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("failed to run %s: %w", client.Path, err)
+		}
+	}
+	return exitCode(client.ProcessState)
+}
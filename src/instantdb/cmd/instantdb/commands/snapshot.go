@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var snapshotName string
+
+// SnapshotCmd returns the snapshot command
+func SnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save and restore copies of instance data",
+		Long: `Snapshots are copies of an instance's data directory, stored in the snapshots
+directory of the instant-db home. A running instance is paused while its data is
+copied and resumed afterwards.
+
+A snapshot can be restored into any instance of the same engine and major version.
+The instance takes over the credentials stored in the snapshot.`,
+	}
+
+	create := &cobra.Command{
+		Use:   "create <instance-name-or-id>",
+		Short: "Snapshot the data of an instance",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runSnapshotCreate,
+	}
+	create.Flags().StringVar(&snapshotName, "name", "", "Snapshot name (default: the current time)")
+	cmd.AddCommand(create)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list [instance-name-or-id]",
+		Short: "List snapshots, optionally of one instance",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runSnapshotList,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "restore <instance-name-or-id> <snapshot>",
+		Short: "Replace the data of an instance with a snapshot",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runSnapshotRestore,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "rm <snapshot>...",
+		Short: "Delete snapshots by name or ID",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runSnapshotRm,
+	})
+
+	return cmd
+}
+
+func runSnapshotCreate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	instance, engine, err := loadInstanceAndEngine(args[0])
+	if err != nil {
+		return err
+	}
+
+	var snapshot *types.Snapshot
+	err = ui.ShowSpinner(fmt.Sprintf("Snapshotting %s", instance.Name), func() error {
+		return engines.WithInstancePaused(ctx, engine, instance, func() error {
+			var err error
+			snapshot, err = utils.CreateSnapshot(instance, snapshotName)
+			return err
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Created snapshot %s of %s (%s)\n", snapshot.Name, instance.Name, ui.FormatBytes(snapshot.Size))))
+	fmt.Println(ui.InfoStyle.Render(fmt.Sprintf("💡 Restore it: instant-db snapshot restore %s %s\n", instance.Name, snapshot.Name)))
+	return nil
+}
+
+func runSnapshotList(cmd *cobra.Command, args []string) error {
+	snapshots, err := utils.ListSnapshots()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		instanceID, err := utils.ResolveInstance(args[0])
+		if err != nil {
+			return err
+		}
+		var filtered []*types.Snapshot
+		for _, snapshot := range snapshots {
+			if snapshot.InstanceID == instanceID {
+				filtered = append(filtered, snapshot)
+			}
+		}
+		snapshots = filtered
+	}
+
+	fmt.Print(ui.RenderSnapshotTable(snapshots))
+	return nil
+}
+
+func runSnapshotRestore(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	instance, engine, err := loadInstanceAndEngine(args[0])
+	if err != nil {
+		return err
+	}
+	snapshot, err := utils.ResolveSnapshot(args[1], instance.ID)
+	if err != nil {
+		return err
+	}
+	if err := checkSnapshotCompatible(snapshot, instance); err != nil {
+		return err
+	}
+
+	err = ui.ShowSpinner(fmt.Sprintf("Restoring %s into %s", snapshot.Name, instance.Name), func() error {
+		return engines.WithInstancePaused(ctx, engine, instance, func() error {
+			return restoreSnapshot(snapshot, instance)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Restored snapshot %s into %s\n", snapshot.Name, instance.Name)))
+	return nil
+}
+
+func runSnapshotRm(cmd *cobra.Command, args []string) error {
+	for _, ref := range args {
+		snapshot, err := utils.ResolveSnapshot(ref, "")
+		if err != nil {
+			return err
+		}
+		if err := utils.RemoveSnapshot(snapshot); err != nil {
+			return err
+		}
+		fmt.Println(ui.SuccessStyle.Render(fmt.Sprintf("✅ Deleted snapshot %s of %s", snapshot.Name, snapshot.Instance)))
+	}
+	fmt.Println()
+	return nil
+}
+
+// loadInstanceAndEngine resolves an instance and the engine managing it
+func loadInstanceAndEngine(nameOrID string) (*types.Instance, engines.Engine, error) {
+	instanceID, err := utils.ResolveInstance(nameOrID)
+	if err != nil {
+		return nil, nil, err
+	}
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("instance not found: %w", err)
+	}
+	engine, err := GetEngine(instance.Engine)
+	if err != nil {
+		return nil, nil, err
+	}
+	return instance, engine, nil
+}
+
+// checkSnapshotCompatible refuses snapshots of another engine, or of a version
+// whose data the instance's release cannot use
+func checkSnapshotCompatible(snapshot *types.Snapshot, instance *types.Instance) error {
+	if snapshot.Engine != instance.Engine {
+		return fmt.Errorf("snapshot %s is of a %s instance, %s is %s", snapshot.Name, snapshot.Engine, instance.Name, instance.Engine)
+	}
+
+	def, err := engines.Lookup(instance.Engine)
+	if err != nil {
+		return err
+	}
+
+	// Records written before versions were tracked use the engine's default
+	snapshotVersion, instanceVersion := snapshot.Version, instance.Version
+	if snapshotVersion == "" {
+		snapshotVersion = def.DefaultVersion
+	}
+	if instanceVersion == "" {
+		instanceVersion = def.DefaultVersion
+	}
+	if def.DataVersion(snapshotVersion) != def.DataVersion(instanceVersion) {
+		return fmt.Errorf("snapshot %s was taken from %s %s, %s runs %s", snapshot.Name, snapshot.Engine, snapshotVersion, instance.Name, instanceVersion)
+	}
+	return nil
+}
+
+// restoreSnapshot replaces the data of a stopped instance with a snapshot. The
+// instance takes over the snapshot's credentials, which live in the data.
+func restoreSnapshot(snapshot *types.Snapshot, instance *types.Instance) error {
+	dataDir, err := utils.SnapshotDataDir(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := utils.ReplaceDir(instance.DataDir, dataDir); err != nil {
+		return err
+	}
+
+	err = utils.UpdateInstance(instance.ID, func(current *types.Instance) error {
+		current.Username = snapshot.Username
+		current.Password = snapshot.Password
+		*instance = *current
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if local, err := localEngine(instance.Engine); err == nil {
+		if relocator, ok := local.(engines.Relocator); ok {
+			return relocator.Relocate(instance)
+		}
+	}
+	return nil
+}
@@ -3,7 +3,13 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/supervisor"
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
 	"github.com/spf13/cobra"
@@ -16,6 +22,11 @@ var (
 	startUsername string
 	startPassword string
 	startEngine   string
+	startVersion  string
+	startWithData string
+	startInitDir  string
+	startRestart  string
+	startTimeout  time.Duration
 )
 
 // StartCmd returns the start command
@@ -23,7 +34,11 @@ func StartCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "start",
 		Short: "Start a new database instance",
-		Long:  `Start a new isolated database instance with automatic configuration.`,
+		Long: `Start a new isolated database instance with automatic configuration.
+
+Defaults for the engine, credentials, version and persistence are read from
+config.yaml in the instant-db home directory (see instant-db config); flags
+take precedence over the file.`,
 		RunE:  runStart,
 	}
 
@@ -32,7 +47,12 @@ func StartCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&startPersist, "persist", false, "Keep data after stop")
 	cmd.Flags().StringVarP(&startUsername, "username", "u", "", "Database username")
 	cmd.Flags().StringVar(&startPassword, "password", "", "Database password")
-	cmd.Flags().StringVarP(&startEngine, "engine", "e", "", "Database engine (postgres, mysql, redis)")
+	cmd.Flags().StringVarP(&startEngine, "engine", "e", "", fmt.Sprintf("Database engine (%s)", strings.Join(engines.Names(), ", ")))
+	cmd.Flags().StringVar(&startVersion, "db-version", "", "Engine version (defaults to the configured or latest supported version)")
+	cmd.Flags().StringVar(&startWithData, "with-data", "", "Load data once the instance is ready: a .sql/.sql.gz file or directory of them, or for redis an .rdb snapshot or a file of commands")
+	cmd.Flags().StringVar(&startInitDir, "init-dir", "", "Run the *.sql, *.sql.gz and *.sh files of a directory in lexical order on the new instance")
+	cmd.Flags().DurationVar(&startTimeout, "timeout", engines.DefaultReadyTimeout, "How long to wait for the instance to accept queries")
+	cmd.Flags().StringVar(&startRestart, "restart", "no", "Restart policy applied by the supervisor (no, on-failure, always)")
 
 	return cmd
 }
@@ -40,12 +60,20 @@ func StartCmd() *cobra.Command {
 func runStart(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
+	// Fall back to the configured default engine
+	if startEngine == "" {
+		startEngine = userConfig.DefaultEngine
+	}
+	if !cmd.Flags().Changed("persist") {
+		startPersist = userConfig.Persist
+	}
+
 	// Interactive mode flag
 	interactiveMode := startEngine == ""
 
 	// Prompt for engine if not provided
 	if startEngine == "" {
-		startEngine = ui.PromptSelect("Select database engine", []string{"postgres", "redis"})
+		startEngine = ui.PromptSelect("Select database engine", engines.InteractiveNames())
 		
 		// Prompt for name in interactive mode
 		if startName == "" {
@@ -54,19 +82,52 @@ func runStart(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate engine
-	if startEngine != "postgres" && startEngine != "mysql" && startEngine != "redis" {
-		return fmt.Errorf("unsupported engine: %s (supported: postgres, mysql, redis)", startEngine)
+	def, err := engines.Lookup(startEngine)
+	if err != nil {
+		return err
+	}
+	startEngine = def.Name
+
+	if err := supervisor.ValidateRestartPolicy(startRestart); err != nil {
+		return err
+	}
+
+	engine, err := GetEngine(def.Name)
+	if err != nil {
+		return err
+	}
+
+	// The engine may load the data from another working directory
+	if startWithData != "" {
+		if startWithData, err = filepath.Abs(startWithData); err != nil {
+			return fmt.Errorf("failed to resolve data path: %w", err)
+		}
+		if _, err := os.Stat(startWithData); err != nil {
+			return fmt.Errorf("failed to read data: %w", err)
+		}
 	}
 
-	// Set defaults
-	defaultUsername := "postgres"
-	defaultPassword := "postgres"
-	if startEngine == "mysql" {
-		defaultUsername = "root"
-		defaultPassword = "password"
-	} else if startEngine == "redis" {
-		defaultUsername = "default"
-		defaultPassword = ""
+	var scripts []string
+	if startInitDir != "" {
+		if startInitDir, err = filepath.Abs(startInitDir); err != nil {
+			return fmt.Errorf("failed to resolve init directory: %w", err)
+		}
+		var skipped []string
+		if scripts, skipped, err = engines.InitScripts(startInitDir); err != nil {
+			return err
+		}
+		if err := checkInitScripts(def, scripts); err != nil {
+			return err
+		}
+		for _, path := range skipped {
+			fmt.Println(ui.MutedStyle.Render(fmt.Sprintf("Ignoring %s", filepath.Base(path))))
+		}
+	}
+
+	// Set defaults, preferring the ones from the config file
+	defaultUsername, defaultPassword, defaultVersion := engineDefaults(def)
+	if startVersion == "" {
+		startVersion = defaultVersion
 	}
 
 	// In interactive mode, ask if user wants to customize credentials
@@ -90,32 +151,61 @@ func runStart(cmd *cobra.Command, args []string) error {
 		Name:     startName,
 		Port:     startPort,
 		Persist:  startPersist,
+		WithData: startWithData,
 		Username: startUsername,
 		Password: startPassword,
 		Engine:   startEngine,
+		Version:  startVersion,
+
+		ReadyTimeout:  startTimeout,
+		RestartPolicy: startRestart,
 	}
 
 	var instance *types.Instance
 	
 	// Show spinner while starting
-	err := ui.ShowSpinner(fmt.Sprintf("Starting %s instance", startEngine), func() error {
+	err = ui.ShowSpinner(fmt.Sprintf("Starting %s instance", startEngine), func() error {
 		var err error
-		if startEngine == "mysql" {
-			instance, err = MySQLEngine.Start(ctx, config)
-		} else if startEngine == "redis" {
-			instance, err = RedisEngine.Start(ctx, config)
-		} else {
-			instance, err = Engine.Start(ctx, config)
-		}
+		instance, err = engine.Start(ctx, config)
 		return err
 	})
 
 	if err != nil {
+		printLogTail(err)
 		return fmt.Errorf("failed to start instance: %w", err)
 	}
 
+	// Init scripts only ever run here, against the freshly initialized instance
+	if len(scripts) > 0 {
+		if err := runInitScripts(ctx, def, instance, scripts); err != nil {
+			engine.Stop(ctx, instance.ID)
+			os.RemoveAll(instance.DataDir)
+			return err
+		}
+	}
+
 	// Render instance details
 	fmt.Println(ui.RenderInstanceDetails(instance))
 
+	if startRestart != supervisor.RestartNo && supervisor.Detect() == nil {
+		fmt.Println(ui.WarningStyle.Render("⚠️  Restart policies are only applied while the supervisor is running: instant-db supervisor start\n"))
+	}
+
 	return nil
 }
+
+// engineDefaults returns the username, password and version used for an engine
+// when none are given: those from the config file, then the engine's own
+func engineDefaults(def *engines.Definition) (username, password, version string) {
+	engineConfig := userConfig.Engines[def.Name]
+
+	username = def.DefaultUsername
+	if engineConfig.Username != "" {
+		username = engineConfig.Username
+	}
+	password = def.DefaultPassword
+	if engineConfig.Password != "" {
+		password = engineConfig.Password
+	}
+	return username, password, engineConfig.Version
+}
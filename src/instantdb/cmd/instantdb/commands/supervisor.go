@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/supervisor"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// supervisorStartTimeout is how long to wait for a spawned supervisor to accept connections
+const supervisorStartTimeout = 5 * time.Second
+
+// SupervisorCmd returns the supervisor command
+func SupervisorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "supervisor",
+		Short: "Manage the background supervisor",
+		Long: `The supervisor is an optional background process that owns all database processes.
+While it is running, start, stop, pause, resume and status are handled by it, and
+instances started with --restart are brought back up after a crash.`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "start",
+		Short: "Start the supervisor in the background",
+		Args:  cobra.NoArgs,
+		RunE:  runSupervisorStart,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "stop",
+		Short: "Stop the supervisor (instances keep running)",
+		Args:  cobra.NoArgs,
+		RunE:  runSupervisorStop,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show whether the supervisor is running",
+		Args:  cobra.NoArgs,
+		RunE:  runSupervisorStatus,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "run",
+		Short: "Run the supervisor in the foreground",
+		Args:  cobra.NoArgs,
+		RunE:  runSupervisorRun,
+	})
+
+	return cmd
+}
+
+func runSupervisorStart(cmd *cobra.Command, args []string) error {
+	if supervisor.Detect() != nil {
+		fmt.Println(ui.InfoStyle.Render("💡 Supervisor is already running"))
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate instant-db executable: %w", err)
+	}
+
+	logPath, err := supervisor.LogPath()
+	if err != nil {
+		return err
+	}
+	logFd, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open supervisor log: %w", err)
+	}
+	defer logFd.Close()
+
+	// Pass the home directory explicitly so a --home flag carries over
+	home, err := utils.HomeDir()
+	if err != nil {
+		return err
+	}
+
+	daemon := exec.Command(executable, "--home", home, "supervisor", "run")
+	daemon.Stdout = logFd
+	daemon.Stderr = logFd
+	utils.DetachProcess(daemon)
+
+	if err := daemon.Start(); err != nil {
+		return fmt.Errorf("failed to start supervisor: %w", err)
+	}
+	daemon.Process.Release()
+
+	deadline := time.Now().Add(supervisorStartTimeout)
+	for supervisor.Detect() == nil {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("supervisor did not come up, see %s", logPath)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	fmt.Println(ui.SuccessStyle.Render("✅ Supervisor started\n"))
+	fmt.Println(ui.MutedStyle.Render(fmt.Sprintf("   Log: %s\n", logPath)))
+
+	return nil
+}
+
+func runSupervisorStop(cmd *cobra.Command, args []string) error {
+	client := supervisor.Detect()
+	if client == nil {
+		fmt.Println(ui.MutedStyle.Render("Supervisor is not running"))
+		return nil
+	}
+
+	if _, err := client.Do(context.Background(), supervisor.Request{Action: supervisor.ActionShutdown}); err != nil {
+		return fmt.Errorf("failed to stop supervisor: %w", err)
+	}
+
+	fmt.Println(ui.SuccessStyle.Render("✅ Supervisor stopped\n"))
+
+	return nil
+}
+
+func runSupervisorStatus(cmd *cobra.Command, args []string) error {
+	client := supervisor.Detect()
+	if client == nil {
+		fmt.Println(ui.MutedStyle.Render("Supervisor is not running\n"))
+		fmt.Println(ui.InfoStyle.Render("💡 Start it: instant-db supervisor start\n"))
+		return nil
+	}
+
+	resp, err := client.Do(context.Background(), supervisor.Request{Action: supervisor.ActionPing})
+	if err != nil {
+		return fmt.Errorf("failed to query supervisor: %w", err)
+	}
+
+	fmt.Println(ui.SuccessStyle.Render("✅ Supervisor is running\n"))
+	fmt.Printf("  PID:               %d\n", resp.PID)
+	fmt.Printf("  Managed instances: %d\n\n", resp.Managed)
+
+	return nil
+}
+
+func runSupervisorRun(cmd *cobra.Command, args []string) error {
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	server := supervisor.NewServer(localEngine, logger)
+	return server.Serve(context.Background())
+}
@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/supervisor"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/ui"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var upFile string
+
+// UpCmd returns the up command
+func UpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Start or resume the instances declared in instantdb.yaml",
+		Long: `Bring up every instance declared in the project manifest (instantdb.yaml in the
+current directory or one of its parents). Missing instances are created and
+seeded, paused ones are resumed and running ones are left alone, so up can be
+run any number of times.
+
+Instances are named <project>/<name>, where the project defaults to the name of
+the manifest directory.
+
+Instances declared with branches: true get a copy per git branch, named
+<project>/<name>@<branch> and cloned from the default branch's instance the
+first time up runs on the branch. url and env return the copy of the branch
+checked out in the project, cloning or resuming it if needed.`,
+		Args: cobra.NoArgs,
+		RunE: runUp,
+	}
+
+	cmd.Flags().StringVarP(&upFile, "file", "f", "", "Path to the manifest (default: closest instantdb.yaml)")
+
+	return cmd
+}
+
+func runUp(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	manifest, err := loadProjectManifest(upFile)
+	if err != nil {
+		return err
+	}
+
+	// Catch unknown engines before starting anything
+	for name, spec := range manifest.Instances {
+		if _, err := engines.Lookup(spec.Engine); err != nil {
+			return fmt.Errorf("instance %s: %w", name, err)
+		}
+	}
+
+	branch, err := utils.ProjectBranch(manifest)
+	if err != nil {
+		return err
+	}
+
+	names := sortedInstanceNames(manifest)
+
+	var results []projectResult
+	err = ui.ShowSpinner(fmt.Sprintf("Bringing up %d instances of %s", len(names), manifest.Project), func() error {
+		results = forEachParallel(ctx, names, func(ctx context.Context, name string) projectResult {
+			return upInstance(ctx, manifest, name, branch)
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return printProjectResults(results)
+}
+
+// upInstance creates, resumes or keeps a single manifest instance, and its copy
+// for branch when it has one
+func upInstance(ctx context.Context, manifest *types.Manifest, name, branch string) projectResult {
+	spec := manifest.Instances[name]
+	result := projectResult{name: utils.ProjectInstanceName(manifest, name)}
+
+	def, err := engines.Lookup(spec.Engine)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	engine, err := GetEngine(def.Name)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	existing, err := findInstanceByName(result.name)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	instance := existing
+	if existing != nil {
+		result.action, result.err = reuseInstance(ctx, engine, existing, manifest, def)
+	} else {
+		instance, result.err = createProjectInstance(ctx, engine, manifest, name, def)
+		result.action = "started"
+	}
+	if result.err != nil {
+		return result
+	}
+
+	if spec.Branches && branch != "" {
+		return upBranchInstance(ctx, engine, manifest, name, def, branch, instance)
+	}
+
+	result.url, _ = engine.GetConnectionURL(instance.ID)
+	return result
+}
+
+// upBranchInstance resumes the copy of a manifest instance for a git branch, or
+// clones it from the default branch's instance
+func upBranchInstance(ctx context.Context, engine engines.Engine, manifest *types.Manifest, name string, def *engines.Definition, branch string, source *types.Instance) projectResult {
+	result := projectResult{name: utils.BranchInstanceName(manifest, name, branch)}
+
+	existing, err := findInstanceByName(result.name)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	instance := existing
+	if existing != nil {
+		result.action, result.err = reuseInstance(ctx, engine, existing, manifest, def)
+	} else {
+		instance, result.err = cloneInstance(ctx, engine, source, engines.CloneOptions{
+			Name:    result.name,
+			Persist: manifest.Instances[name].Persist,
+			Project: manifest.Dir,
+			Branch:  branch,
+		})
+		result.action = "cloned from " + source.Name
+	}
+	if result.err != nil {
+		return result
+	}
+
+	result.url, _ = engine.GetConnectionURL(instance.ID)
+	return result
+}
+
+// reuseInstance resumes an existing project instance if needed
+func reuseInstance(ctx context.Context, engine engines.Engine, instance *types.Instance, manifest *types.Manifest, def *engines.Definition) (string, error) {
+	if instance.Project != manifest.Dir {
+		return "", fmt.Errorf("instance name is already used by %s; set a different project: in %s", describeOwner(instance), utils.ManifestFileName)
+	}
+	if instance.Engine != def.Name {
+		return "", fmt.Errorf("exists as a %s instance; remove it with instant-db down --stop to switch engines", instance.Engine)
+	}
+
+	status, err := engine.Status(ctx, instance.ID)
+	if err != nil {
+		return "", err
+	}
+
+	switch status.State {
+	case types.StateRunning:
+		return "already running", nil
+	case types.StatePaused:
+		if err := engine.Resume(ctx, instance.ID); err != nil {
+			return "", fmt.Errorf("failed to resume: %w", err)
+		}
+		return "resumed", nil
+	}
+	return "", fmt.Errorf("instance is %s (%s); check it with instant-db prune --dry-run", status.State, status.Message)
+}
+
+// createProjectInstance starts a new project instance and loads its seed files.
+// A failed seed removes the instance again so the next up starts over.
+func createProjectInstance(ctx context.Context, engine engines.Engine, manifest *types.Manifest, name string, def *engines.Definition) (*types.Instance, error) {
+	spec := manifest.Instances[name]
+
+	username, password, version := engineDefaults(def)
+	if spec.Username != "" {
+		username = spec.Username
+	}
+	if spec.Password != "" {
+		password = spec.Password
+	}
+	if spec.Version != "" {
+		version = spec.Version
+	}
+
+	var seeder engines.Seeder
+	if len(spec.Seed) > 0 {
+		local, _ := localEngine(def.Name)
+		var ok bool
+		if seeder, ok = local.(engines.Seeder); !ok {
+			return nil, fmt.Errorf("seed files are not supported for %s instances", def.Name)
+		}
+	}
+
+	instance, err := engine.Start(ctx, types.Config{
+		Name:     utils.ProjectInstanceName(manifest, name),
+		Port:     spec.Port,
+		Persist:  spec.Persist,
+		Username: username,
+		Password: password,
+		Engine:   def.Name,
+		Version:  version,
+		Project:  manifest.Dir,
+
+		RestartPolicy: supervisor.RestartNo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start: %w", err)
+	}
+
+	for _, seed := range spec.Seed {
+		if err := seeder.Seed(ctx, instance.ID, seed); err != nil {
+			engine.Stop(ctx, instance.ID)
+			os.RemoveAll(instance.DataDir)
+			return nil, fmt.Errorf("failed to seed: %w", err)
+		}
+	}
+
+	return instance, nil
+}
+
+// describeOwner names what an instance belongs to for error messages
+func describeOwner(instance *types.Instance) string {
+	if instance.Project == "" {
+		return "an instance started outside of a project"
+	}
+	return "the project in " + instance.Project
+}
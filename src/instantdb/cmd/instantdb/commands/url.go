@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +18,7 @@ func URLCmd() *cobra.Command {
 }
 
 func runURL(cmd *cobra.Command, args []string) error {
-	instanceID, err := utils.ResolveInstance(args[0])
+	instanceID, err := resolveProjectInstance(args[0])
 	if err != nil {
 		return err
 	}
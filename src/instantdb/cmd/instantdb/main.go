@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -17,6 +18,11 @@ func main() {
 	rootCmd := commands.GetRootCommand(version)
 	
 	if err := rootCmd.Execute(); err != nil {
+		// The run command passes on the exit code of its child
+		var exitErr *commands.ExitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -0,0 +1,21 @@
+package engines
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// defaultMirror hosts the prebuilt engine binaries released with instant-db
+const defaultMirror = "https://github.com/db-toolkit/instantdb/releases/download/binaries-v0.1.0"
+
+// downloadMu keeps instances started in parallel from downloading the same binaries twice
+var downloadMu sync.Mutex
+
+// versionDir returns the directory caching one release of an engine's binaries.
+// The default release stays at the root of the cache so existing downloads are reused.
+func versionDir(binaryDir, version, defaultVersion string) string {
+	if version == "" || version == defaultVersion {
+		return binaryDir
+	}
+	return filepath.Join(binaryDir, version)
+}
@@ -0,0 +1,116 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// CloneOptions are the settings in which a clone differs from its source
+type CloneOptions struct {
+	Name    string
+	Port    int
+	Persist bool
+
+	// Project and Branch tie the clone to a git branch of a project
+	Project string
+	Branch  string
+
+	// Relocator adopts the copied data directory, nil when the engine keeps no
+	// instance settings in it
+	Relocator Relocator
+}
+
+// Clone registers a new instance with a copy of the source's data and starts
+// it by resuming it. The clone is removed again if any step fails.
+func Clone(ctx context.Context, engine Engine, source *types.Instance, options CloneOptions) (*types.Instance, error) {
+	baseDir, err := utils.DataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	clone := *source
+	clone.ID = utils.GenerateID()
+	clone.Name = options.Name
+	clone.Port = options.Port
+	clone.DataDir = filepath.Join(baseDir, clone.ID)
+	clone.Persist = options.Persist
+	clone.Project = options.Project
+	clone.Branch = options.Branch
+	clone.PID = 0
+	clone.PGID = 0
+	clone.Paused = false
+	clone.Status = types.StateStarting
+	clone.CreatedAt = time.Now().Unix()
+
+	// Claim the name and port; the reservation is dropped if the clone fails
+	if err := utils.ReserveInstance(&clone); err != nil {
+		return nil, err
+	}
+	cloned := false
+	defer func() {
+		if !cloned {
+			os.RemoveAll(clone.DataDir)
+			utils.RemoveInstance(clone.ID)
+		}
+	}()
+
+	err = WithInstancePaused(ctx, engine, source, func() error {
+		return utils.CopyDir(source.DataDir, clone.DataDir)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy data: %w", err)
+	}
+
+	if options.Relocator != nil {
+		if err := options.Relocator.Relocate(&clone); err != nil {
+			return nil, err
+		}
+	}
+
+	// The copied data is started like a paused instance
+	clone.Paused = true
+	clone.Status = types.StatePaused
+	if err := utils.SaveInstance(&clone); err != nil {
+		return nil, fmt.Errorf("failed to save instance: %w", err)
+	}
+	if err := engine.Resume(ctx, clone.ID); err != nil {
+		return nil, fmt.Errorf("failed to start clone: %w", err)
+	}
+
+	cloned = true
+	return utils.LoadInstance(clone.ID)
+}
+
+// WithInstancePaused runs fn while the instance is not running, pausing and
+// resuming a running instance around it
+func WithInstancePaused(ctx context.Context, engine Engine, instance *types.Instance, fn func() error) (err error) {
+	status, err := engine.Status(ctx, instance.ID)
+	if err != nil {
+		return err
+	}
+
+	switch status.State {
+	case types.StatePaused:
+		return fn()
+	case types.StateRunning:
+	default:
+		return fmt.Errorf("instance is %s (%s); check it with instant-db prune --dry-run", status.State, status.Message)
+	}
+
+	if err := engine.Pause(ctx, instance.ID); err != nil {
+		return fmt.Errorf("failed to pause: %w", err)
+	}
+	defer func() {
+		if resumeErr := engine.Resume(ctx, instance.ID); resumeErr != nil && err == nil {
+			err = fmt.Errorf("failed to resume: %w", resumeErr)
+		}
+	}()
+
+	return fn()
+}
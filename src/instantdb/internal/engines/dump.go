@@ -0,0 +1,153 @@
+package engines
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Dump formats
+const (
+	DumpFormatSQL  = "sql"
+	DumpFormatJSON = "json"
+	DumpFormatRDB  = "rdb"
+)
+
+// dumpBatchRows is the number of rows written per INSERT statement
+const dumpBatchRows = 100
+
+// dumpWriter writes a dump through a buffer, keeping the first write error
+type dumpWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func newDumpWriter(w io.Writer) *dumpWriter {
+	return &dumpWriter{w: bufio.NewWriterSize(w, 64*1024)}
+}
+
+func (d *dumpWriter) printf(format string, args ...interface{}) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, args...)
+	}
+}
+
+// statement writes a statement followed by a semicolon
+func (d *dumpWriter) statement(statement string) {
+	d.printf("%s;\n", strings.TrimRight(strings.TrimSpace(statement), ";"))
+}
+
+// close flushes the buffer and returns the first error
+func (d *dumpWriter) close() error {
+	if d.err == nil {
+		d.err = d.w.Flush()
+	}
+	if d.err != nil {
+		return fmt.Errorf("failed to write dump: %w", d.err)
+	}
+	return nil
+}
+
+// insertBatcher groups rows into multi-row INSERT statements
+type insertBatcher struct {
+	out    *dumpWriter
+	prefix string
+	rows   int
+}
+
+// add writes a row of already quoted values
+func (b *insertBatcher) add(values []string) {
+	if b.rows == 0 {
+		b.out.printf("%s\n  (%s)", b.prefix, strings.Join(values, ", "))
+	} else {
+		b.out.printf(",\n  (%s)", strings.Join(values, ", "))
+	}
+	b.rows++
+	if b.rows == dumpBatchRows {
+		b.flush()
+	}
+}
+
+// flush ends the statement in progress
+func (b *insertBatcher) flush() {
+	if b.rows > 0 {
+		b.out.printf(";\n")
+		b.rows = 0
+	}
+}
+
+// loadSQLDump runs a SQL dump statement by statement on a single connection
+func loadSQLDump(ctx context.Context, driver, dsn string, r io.Reader, progress func(done, total int)) error {
+	script, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read dump: %w", err)
+	}
+	statements := SplitSQL(string(script), driver == "mysql")
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	for i, statement := range statements {
+		if err := execSQLStatement(ctx, conn, statement); err != nil {
+			return &SeedError{File: "dump", Line: statement.Line, Statement: statement.Text, Err: err}
+		}
+		progress(i+1, len(statements))
+	}
+
+	return nil
+}
+
+// queryer runs queries, on a database, connection or transaction
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryStrings runs a query and returns every row as strings, NULL as ""
+func queryStrings(ctx context.Context, q queryer, query string, args ...interface{}) ([][]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result [][]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		targets := make([]interface{}, len(columns))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+
+		row := make([]string, len(columns))
+		for i, value := range values {
+			row[i] = value.String
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// unsupportedDumpFormat reports a format an engine cannot dump or load
+func unsupportedDumpFormat(engine, format string) error {
+	return fmt.Errorf("%s does not support %s dumps", engine, format)
+}
@@ -2,6 +2,8 @@ package engines
 
 import (
 	"context"
+	"io"
+	"os/exec"
 	
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
 )
@@ -29,3 +31,49 @@ type Engine interface {
 	// List returns all running instances for this engine
 	List() ([]*types.Instance, error)
 }
+
+// MirrorSetter is implemented by engines that download their binaries and can
+// fetch them from a different location
+type MirrorSetter interface {
+	// SetMirror replaces the base URL binaries are downloaded from
+	SetMirror(url string)
+}
+
+// Seeder is implemented by engines that can load seed files into a running instance
+type Seeder interface {
+	// Seed runs the statements of a seed file, or a directory of them, against an
+	// instance; a failing statement is reported as a *SeedError
+	Seed(ctx context.Context, instanceID, path string) error
+}
+
+// Relocator is implemented by engines that keep instance settings such as the
+// port inside the data directory, so data copied from another instance can be adopted
+type Relocator interface {
+	// Relocate rewrites the settings in an instance's data directory to match its metadata
+	Relocate(instance *types.Instance) error
+}
+
+// Dumper is implemented by engines that can write and load logical dumps
+type Dumper interface {
+	// DumpFormats lists the dump formats of the engine, the default first
+	DumpFormats() []string
+
+	// Dump writes a dump of a running instance in the given format
+	Dump(ctx context.Context, instanceID, format string, w io.Writer) error
+
+	// Load loads a dump in the given format into an instance, reporting how
+	// much of it is done. It is called on a paused instance for the formats
+	// OfflineLoad reports, on a running one otherwise.
+	Load(ctx context.Context, instanceID, format string, r io.Reader, progress func(done, total int)) error
+
+	// OfflineLoad reports whether a format can only be loaded while the instance is paused
+	OfflineLoad(format string) bool
+}
+
+// Shell is implemented by engines whose command-line client can be opened on an instance
+type Shell interface {
+	// ShellCommand returns the client connected to an instance, running query
+	// and exiting when one is given. The credentials are passed without
+	// appearing on the command line; cleanup removes whatever held them.
+	ShellCommand(instance *types.Instance, query string) (cmd *exec.Cmd, cleanup func(), err error)
+}
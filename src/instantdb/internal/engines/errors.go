@@ -0,0 +1,46 @@
+package engines
+
+import (
+	"os"
+	"strings"
+)
+
+// logTailLines is the number of log lines attached to a StartError
+const logTailLines = 20
+
+// StartError is returned when an engine process was launched but did not come up.
+// It carries the tail of the engine log to help diagnose the failure.
+type StartError struct {
+	Err     error
+	LogTail string
+}
+
+func (e *StartError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// newStartError wraps err with the last lines of the log file at logPath
+func newStartError(err error, logPath string) *StartError {
+	return &StartError{
+		Err:     err,
+		LogTail: tailFile(logPath, logTailLines),
+	}
+}
+
+// tailFile returns the last n lines of a file, or an empty string if it cannot be read
+func tailFile(path string, n int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,106 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// IsInitSQL reports whether an init script holds SQL statements
+func IsInitSQL(name string) bool {
+	return strings.HasSuffix(name, ".sql") || strings.HasSuffix(name, ".sql.gz")
+}
+
+// InitScripts returns the *.sql, *.sql.gz and *.sh files of an init directory
+// in lexical order, following the docker-entrypoint-initdb.d convention.
+// Other files are returned as skipped.
+func InitScripts(dir string) (scripts, skipped []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read init directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if IsInitSQL(entry.Name()) || strings.HasSuffix(entry.Name(), ".sh") {
+			scripts = append(scripts, path)
+		} else {
+			skipped = append(skipped, path)
+		}
+	}
+
+	return scripts, skipped, nil
+}
+
+// CheckInitScripts makes sure an engine can run every script before an
+// instance is started. SQL scripts need an engine that speaks SQL.
+func CheckInitScripts(def *Definition, engine Engine, scripts []string) error {
+	_, seeder := engine.(Seeder)
+	for _, script := range scripts {
+		if IsInitSQL(script) && (!def.SQL || !seeder) {
+			return fmt.Errorf("SQL init scripts are not supported for %s instances: %s", def.Name, filepath.Base(script))
+		}
+	}
+	return nil
+}
+
+// ApplyInitScripts runs init scripts in order against a freshly started
+// instance and records them in its metadata. SQL files go through the engine's
+// seeder; shell scripts run with env as their environment. Before each script
+// runs, it is passed to progress.
+func ApplyInitScripts(ctx context.Context, engine Engine, instance *types.Instance, scripts, env []string, progress func(script string)) error {
+	var applied []string
+	for _, script := range scripts {
+		progress(script)
+
+		var err error
+		if IsInitSQL(script) {
+			seeder, ok := engine.(Seeder)
+			if !ok {
+				return fmt.Errorf("SQL init scripts are not supported for %s instances: %s", instance.Engine, filepath.Base(script))
+			}
+			err = seeder.Seed(ctx, instance.ID, script)
+		} else {
+			err = runShellScript(ctx, script, env)
+		}
+		if err != nil {
+			return fmt.Errorf("init script %s failed: %w", filepath.Base(script), err)
+		}
+
+		applied = append(applied, script)
+	}
+
+	err := utils.UpdateInstance(instance.ID, func(current *types.Instance) error {
+		current.InitScripts = applied
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save instance: %w", err)
+	}
+	instance.InitScripts = applied
+	return nil
+}
+
+// runShellScript runs an init shell script from its directory. Executable
+// scripts run directly, others through sh.
+func runShellScript(ctx context.Context, script string, env []string) error {
+	command := exec.CommandContext(ctx, "sh", script)
+	if info, err := os.Stat(script); err == nil && info.Mode()&0111 != 0 {
+		command = exec.CommandContext(ctx, script)
+	}
+
+	command.Dir = filepath.Dir(script)
+	command.Env = env
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return command.Run()
+}
@@ -0,0 +1,150 @@
+package engines
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+)
+
+const (
+	// LogMaxSize is the size above which an engine log is rotated
+	LogMaxSize = 10 * 1024 * 1024
+
+	// LogKeep is the number of rotated logs kept next to the current one
+	LogKeep = 3
+)
+
+// InstanceLogFile returns the path of the log an instance's engine writes
+// into its data directory
+func InstanceLogFile(instance *types.Instance) (string, error) {
+	def, err := Lookup(instance.Engine)
+	if err != nil {
+		return "", err
+	}
+	if def.LogFile == "" {
+		return "", fmt.Errorf("%s instances keep no log instant-db can read", def.Name)
+	}
+	return filepath.Join(instance.DataDir, def.LogFile), nil
+}
+
+// LogFiles returns the existing rotated logs of path, oldest first, followed
+// by path itself
+func LogFiles(path string) []string {
+	var files []string
+	for i := LogKeep; i >= 1; i-- {
+		rotated := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(rotated); err == nil {
+			files = append(files, rotated)
+		}
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files
+}
+
+// RotateLog moves a log that outgrew LogMaxSize to path.1, shifting older
+// ones up to path.LogKeep. The log is copied and truncated rather than moved,
+// so a server writing to it in append mode carries on in the emptied file.
+func RotateLog(path string) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < LogMaxSize {
+		return nil
+	}
+
+	for i := LogKeep - 1; i >= 1; i-- {
+		older := fmt.Sprintf("%s.%d", path, i)
+		if err := os.Rename(older, fmt.Sprintf("%s.%d", path, i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate log: %w", err)
+		}
+	}
+
+	if err := copyLog(path, path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate log: %w", err)
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		return fmt.Errorf("failed to rotate log: %w", err)
+	}
+	return nil
+}
+
+// copyLog copies the current content of a log
+func copyLog(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// openLog rotates a log if needed and opens it for appending, for use as the
+// output of an engine process
+func openLog(path string) (*os.File, error) {
+	if err := RotateLog(path); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+}
+
+// logFileWriter appends every write to a log file, opening it each time so
+// no file handle has to outlive the command that started the engine
+type logFileWriter string
+
+func (w logFileWriter) Write(data []byte) (int, error) {
+	file, err := os.OpenFile(string(w), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return file.Write(data)
+}
+
+// logTimeFormats recognizes the timestamps that start the lines of engine logs
+var logTimeFormats = []struct {
+	pattern *regexp.Regexp
+	layout  string
+	local   bool
+}{
+	// PostgreSQL: 2024-01-02 15:04:05.123 UTC [123] LOG: ...
+	{regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? UTC)`), "2006-01-02 15:04:05 UTC", false},
+	// MySQL: 2024-01-02T15:04:05.123456Z 0 [System] ...
+	{regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2}))`), time.RFC3339, false},
+	// Redis: 1234:M 02 Jan 2024 15:04:05.123 * Ready to accept connections
+	{regexp.MustCompile(`^\d+:[A-Z] (\d{2} [A-Z][a-z]{2} \d{4} \d{2}:\d{2}:\d{2}(\.\d+)?)`), "02 Jan 2006 15:04:05", true},
+}
+
+// LogTime returns the time at the start of an engine log line. Continuation
+// lines of multi-line messages have none.
+func LogTime(line string) (time.Time, bool) {
+	for _, format := range logTimeFormats {
+		match := format.pattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		location := time.UTC
+		if format.local {
+			location = time.Local
+		}
+		// Fractional seconds are accepted after the seconds of any layout
+		if t, err := time.ParseInLocation(format.layout, match[1], location); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -4,9 +4,11 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"database/sql"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,22 +19,58 @@ import (
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
 )
 
+func init() {
+	Register(&Definition{
+		Name:            "mysql",
+		DisplayName:     "MySQL",
+		DefaultUsername: "root",
+		DefaultPassword: "password",
+		DefaultVersion:  mysqlDefaultVersion,
+		ReleaseBinaries: true,
+		URLEnv:          "DATABASE_URL",
+		EnvPrefix:       "MYSQL_",
+		ProcessNames:    []string{"mysqld"},
+		SQL:             true,
+		LogFile:         "mysql.log",
+
+		// An 8.4 data directory cannot be started by 8.0
+		DataVersionParts: 2,
+
+		New: func(baseDir string) Engine {
+			return NewMySQLEngine(baseDir)
+		},
+	})
+}
+
+// mysqlDefaultVersion is the MySQL release used when no version is requested
+const mysqlDefaultVersion = "8.0.40"
+
 type MySQLEngine struct {
 	baseDir   string
 	binaryDir string
+	mirror    string
 }
 
 func NewMySQLEngine(baseDir string) *MySQLEngine {
-	homeDir, _ := os.UserHomeDir()
-	binaryDir := filepath.Join(homeDir, ".instant-db-mysql")
+	binaryDir, _ := utils.BinaryDir("mysql", ".instant-db-mysql")
 	return &MySQLEngine{
 		baseDir:   baseDir,
 		binaryDir: binaryDir,
+		mirror:    defaultMirror,
 	}
 }
 
-func (e *MySQLEngine) downloadMySQL() error {
-	version := "8.0.40"
+// SetMirror replaces the base URL MySQL binaries are downloaded from
+func (e *MySQLEngine) SetMirror(url string) {
+	e.mirror = url
+}
+
+// versionDir returns the directory holding the binaries of a MySQL release
+func (e *MySQLEngine) versionDir(version string) string {
+	return versionDir(e.binaryDir, version, mysqlDefaultVersion)
+}
+
+func (e *MySQLEngine) downloadMySQL(dir, version string) error {
 	platform := "darwin-universal"
 	
 	if runtime.GOOS == "linux" {
@@ -46,9 +84,9 @@ func (e *MySQLEngine) downloadMySQL() error {
 		ext = ".zip"
 	}
 	
-	url := fmt.Sprintf("https://github.com/db-toolkit/instantdb/releases/download/binaries-v0.1.0/mysql-%s-%s%s", version, platform, ext)
+	url := fmt.Sprintf("%s/mysql-%s-%s%s", e.mirror, version, platform, ext)
 	
-	tmpFile := filepath.Join(e.binaryDir, "mysql"+ext)
+	tmpFile := filepath.Join(dir, "mysql"+ext)
 	
 	resp, err := http.Get(url)
 	if err != nil {
@@ -56,6 +94,10 @@ func (e *MySQLEngine) downloadMySQL() error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download mysql %s from %s: %s", version, url, resp.Status)
+	}
+
 	out, err := os.Create(tmpFile)
 	if err != nil {
 		return err
@@ -95,7 +137,7 @@ func (e *MySQLEngine) downloadMySQL() error {
 				return err
 			}
 			
-			target := filepath.Join(e.binaryDir, header.Name)
+			target := filepath.Join(dir, header.Name)
 			
 			switch header.Typeflag {
 			case tar.TypeDir:
@@ -116,8 +158,8 @@ func (e *MySQLEngine) downloadMySQL() error {
 		}
 		
 		// Copy lib files to bin directory for @loader_path resolution (macOS/Linux)
-		libDir := filepath.Join(e.binaryDir, "lib")
-		binDir := filepath.Join(e.binaryDir, "bin")
+		libDir := filepath.Join(dir, "lib")
+		binDir := filepath.Join(dir, "bin")
 		if entries, err := os.ReadDir(libDir); err == nil {
 			for _, entry := range entries {
 				if !entry.IsDir() {
@@ -140,17 +182,24 @@ func (e *MySQLEngine) downloadMySQL() error {
 	return nil
 }
 
-func (e *MySQLEngine) ensureMySQL() (string, error) {
-	mysqlBinary := filepath.Join(e.binaryDir, "bin", "mysqld")
+func (e *MySQLEngine) ensureMySQL(version string) (string, error) {
+	if version == "" {
+		version = mysqlDefaultVersion
+	}
+	dir := e.versionDir(version)
+	mysqlBinary := filepath.Join(dir, "bin", "mysqld")
+
+	downloadMu.Lock()
+	defer downloadMu.Unlock()
 	
 	if _, err := os.Stat(mysqlBinary); err == nil {
 		return mysqlBinary, nil
 	}
 
-	os.MkdirAll(e.binaryDir, 0755)
+	os.MkdirAll(dir, 0755)
 
-	fmt.Println("📦 Downloading MySQL binaries (first time only)...")
-	if err := e.downloadMySQL(); err != nil {
+	fmt.Printf("📦 Downloading MySQL %s binaries (first time only)...\n", version)
+	if err := e.downloadMySQL(dir, version); err != nil {
 		return "", fmt.Errorf("failed to setup mysql: %w", err)
 	}
 
@@ -163,38 +212,52 @@ func (e *MySQLEngine) Start(ctx context.Context, config types.Config) (*types.In
 	if config.Name == "" {
 		config.Name = fmt.Sprintf("mysql-%s", instanceID[:8])
 	}
-	if config.Port == 0 {
-		port, err := utils.GetFreePort()
-		if err != nil {
-			return nil, fmt.Errorf("failed to allocate port: %w", err)
-		}
-		config.Port = port
-	}
 	if config.DataDir == "" {
 		config.DataDir = filepath.Join(e.baseDir, instanceID)
 	}
 	if config.Username == "" {
 		config.Username = "root"
 	}
+	if config.Version == "" {
+		config.Version = mysqlDefaultVersion
+	}
 	if config.Password == "" {
 		config.Password = ""
 	}
 
+	// Claim the name and port; the reservation is dropped if the start fails
+	instance, err := reserveInstance(instanceID, "mysql", &config)
+	if err != nil {
+		return nil, err
+	}
+	started := false
+	defer func() {
+		if !started {
+			utils.RemoveInstance(instanceID)
+		}
+	}()
+
 	if err := os.MkdirAll(config.DataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	mysqlBinary, err := e.ensureMySQL()
+	mysqlBinary, err := e.ensureMySQL(config.Version)
 	if err != nil {
 		return nil, err
 	}
 
+	logFile := filepath.Join(config.DataDir, "mysql.log")
+	logFd, _ := openLog(logFile)
+
 	// Initialize MySQL data directory
 	initCmd := exec.Command(mysqlBinary, "--initialize-insecure", "--datadir="+config.DataDir)
-	initCmd.Env = append(os.Environ(), getLibraryPathEnv(e.binaryDir))
+	initCmd.Env = append(os.Environ(), getLibraryPathEnv(e.versionDir(config.Version)))
+	initCmd.Stdout = logFd
+	initCmd.Stderr = logFd
 	if err := initCmd.Run(); err != nil {
+		startErr := newStartError(fmt.Errorf("failed to initialize mysql: %w", err), logFile)
 		os.RemoveAll(config.DataDir)
-		return nil, fmt.Errorf("failed to initialize mysql: %w", err)
+		return nil, startErr
 	}
 
 	// Start MySQL
@@ -203,58 +266,118 @@ func (e *MySQLEngine) Start(ctx context.Context, config types.Config) (*types.In
 		"--port="+fmt.Sprintf("%d", config.Port),
 		"--bind-address=127.0.0.1",
 	)
-	cmd.Env = append(os.Environ(), getLibraryPathEnv(e.binaryDir))
-	
-	logFile := filepath.Join(config.DataDir, "mysql.log")
-	logFd, _ := os.Create(logFile)
+	cmd.Env = append(os.Environ(), getLibraryPathEnv(e.versionDir(config.Version)))
 	cmd.Stdout = logFd
 	cmd.Stderr = logFd
+	utils.SetProcessGroup(cmd)
 	
 	if err := cmd.Start(); err != nil {
 		os.RemoveAll(config.DataDir)
 		return nil, fmt.Errorf("failed to start mysql: %w", err)
 	}
 
-	time.Sleep(2 * time.Second)
+	exited := watchProcess(cmd)
+
+	// A freshly initialized server only has a passwordless root account
+	probe := sqlProbe("mysql", mysqlDSN(config.Port, "root", "", "mysql"))
+	if err := waitForReady(ctx, config.ReadyTimeout, probe, exited); err != nil {
+		cmd.Process.Kill()
+		startErr := newStartError(fmt.Errorf("mysql failed to start: %w", err), logFile)
+		os.RemoveAll(config.DataDir)
+		return nil, startErr
+	}
+
+	if err := e.applyCredentials(ctx, config.Port, config.Username, config.Password); err != nil {
+		cmd.Process.Kill()
+		os.RemoveAll(config.DataDir)
+		return nil, fmt.Errorf("failed to configure credentials: %w", err)
+	}
 
-	instance := &types.Instance{
-		ID:        instanceID,
-		Name:      config.Name,
-		Engine:    "mysql",
-		Port:      config.Port,
-		DataDir:   config.DataDir,
-		PID:       cmd.Process.Pid,
-		Status:    "running",
-		CreatedAt: time.Now().Unix(),
-		Persist:   config.Persist,
-		Username:  config.Username,
-		Password:  config.Password,
+	if config.WithData != "" {
+		if err := loadSQLSeed(ctx, "mysql", mysqlDSN(config.Port, config.Username, config.Password, "mysql"), config.WithData); err != nil {
+			cmd.Process.Kill()
+			os.RemoveAll(config.DataDir)
+			return nil, err
+		}
 	}
 
+	instance.PID = cmd.Process.Pid
+	instance.PGID = utils.ProcessGroup(cmd.Process.Pid)
+	instance.StartedAt = time.Now().Unix()
+	instance.Status = "running"
+
 	if err := utils.SaveInstance(instance); err != nil {
-		utils.KillProcessOnPort(config.Port)
+		cmd.Process.Kill()
 		os.RemoveAll(config.DataDir)
 		return nil, fmt.Errorf("failed to save instance: %w", err)
 	}
 
+	started = true
 	return instance, nil
 }
 
+// Seed runs a SQL file, a .sql.gz file or a directory of them against the mysql
+// database of an instance, the one its URL points to
+func (e *MySQLEngine) Seed(ctx context.Context, instanceID, path string) error {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	return loadSQLSeed(ctx, "mysql", mysqlDSN(instance.Port, instance.Username, instance.Password, "mysql"), path)
+}
+
+// applyCredentials sets up the requested account on a freshly initialized server
+func (e *MySQLEngine) applyCredentials(ctx context.Context, port int, username, password string) error {
+	if username == "root" && password == "" {
+		return nil
+	}
+
+	db, err := sql.Open("mysql", mysqlDSN(port, "root", "", "mysql"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var statements []string
+	if username == "root" {
+		statements = []string{
+			fmt.Sprintf("ALTER USER 'root'@'localhost' IDENTIFIED BY %s", mysqlQuote(password)),
+		}
+	} else {
+		user := mysqlQuote(username) + "@'%'"
+		statements = []string{
+			fmt.Sprintf("CREATE USER %s IDENTIFIED BY %s", user, mysqlQuote(password)),
+			fmt.Sprintf("GRANT ALL PRIVILEGES ON *.* TO %s WITH GRANT OPTION", user),
+		}
+	}
+
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (e *MySQLEngine) Stop(ctx context.Context, instanceID string) error {
 	instance, err := utils.LoadInstance(instanceID)
 	if err != nil {
 		return fmt.Errorf("instance not found: %w", err)
 	}
 
-	utils.KillProcessOnPort(instance.Port)
+	if err := stopProcess(instance, "mysqld"); err != nil {
+		return err
+	}
 
 	if !instance.Persist {
-		os.RemoveAll(instance.DataDir)
+		if err := os.RemoveAll(instance.DataDir); err != nil {
+			return fmt.Errorf("failed to remove data directory: %w", err)
+		}
 	}
 
-	metaFile := filepath.Join(os.Getenv("HOME"), ".instant-db", instanceID+".json")
-	os.Remove(metaFile)
-	return nil
+	return utils.RemoveInstance(instanceID)
 }
 
 func (e *MySQLEngine) Pause(ctx context.Context, instanceID string) error {
@@ -263,11 +386,21 @@ func (e *MySQLEngine) Pause(ctx context.Context, instanceID string) error {
 		return fmt.Errorf("instance not found: %w", err)
 	}
 
-	utils.KillProcessOnPort(instance.Port)
+	if instance.Paused {
+		return fmt.Errorf("instance is already paused")
+	}
+
+	if err := stopProcess(instance, "mysqld"); err != nil {
+		return err
+	}
 
-	instance.Status = "paused"
-	instance.Paused = true
-	return utils.SaveInstance(instance)
+	return utils.UpdateInstance(instanceID, func(instance *types.Instance) error {
+		instance.PID = 0
+		instance.PGID = 0
+		instance.Status = "paused"
+		instance.Paused = true
+		return nil
+	})
 }
 
 func (e *MySQLEngine) Resume(ctx context.Context, instanceID string) error {
@@ -276,7 +409,11 @@ func (e *MySQLEngine) Resume(ctx context.Context, instanceID string) error {
 		return fmt.Errorf("instance not found: %w", err)
 	}
 
-	mysqlBinary, err := e.ensureMySQL()
+	if !instance.Paused {
+		return fmt.Errorf("instance is not paused")
+	}
+
+	mysqlBinary, err := e.ensureMySQL(instance.Version)
 	if err != nil {
 		return err
 	}
@@ -286,23 +423,34 @@ func (e *MySQLEngine) Resume(ctx context.Context, instanceID string) error {
 		"--port="+fmt.Sprintf("%d", instance.Port),
 		"--bind-address=127.0.0.1",
 	)
-	cmd.Env = append(os.Environ(), getLibraryPathEnv(e.binaryDir))
+	cmd.Env = append(os.Environ(), getLibraryPathEnv(e.versionDir(instance.Version)))
 	
 	logFile := filepath.Join(instance.DataDir, "mysql.log")
-	logFd, _ := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	logFd, _ := openLog(logFile)
 	cmd.Stdout = logFd
 	cmd.Stderr = logFd
+	utils.SetProcessGroup(cmd)
 
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start mysql: %w", err)
 	}
 
-	time.Sleep(2 * time.Second)
+	exited := watchProcess(cmd)
 
-	instance.PID = cmd.Process.Pid
-	instance.Status = "running"
-	instance.Paused = false
-	return utils.SaveInstance(instance)
+	probe := sqlProbe("mysql", mysqlDSN(instance.Port, instance.Username, instance.Password, "mysql"))
+	if err := waitForReady(ctx, instance.ReadyTimeout, probe, exited); err != nil {
+		cmd.Process.Kill()
+		return newStartError(fmt.Errorf("mysql failed to start: %w", err), logFile)
+	}
+
+	return utils.UpdateInstance(instanceID, func(instance *types.Instance) error {
+		instance.PID = cmd.Process.Pid
+		instance.PGID = utils.ProcessGroup(cmd.Process.Pid)
+		instance.StartedAt = time.Now().Unix()
+		instance.Status = "running"
+		instance.Paused = false
+		return nil
+	})
 }
 
 func (e *MySQLEngine) GetStatus(ctx context.Context, instanceID string) (string, error) {
@@ -318,7 +466,7 @@ func (e *MySQLEngine) GetConnectionURL(instanceID string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("instance not found: %w", err)
 	}
-	return fmt.Sprintf("mysql://%s:%s@127.0.0.1:%d/mysql", instance.Username, instance.Password, instance.Port), nil
+	return fmt.Sprintf("mysql://%s@127.0.0.1:%d/mysql", url.UserPassword(instance.Username, instance.Password), instance.Port), nil
 }
 
 func (e *MySQLEngine) Status(ctx context.Context, instanceID string) (*types.Status, error) {
@@ -326,10 +474,8 @@ func (e *MySQLEngine) Status(ctx context.Context, instanceID string) (*types.Sta
 	if err != nil {
 		return nil, fmt.Errorf("instance not found: %w", err)
 	}
-	return &types.Status{
-		Running: instance.Status == "running",
-		Message: instance.Status,
-	}, nil
+	probe := sqlProbe("mysql", mysqlDSN(instance.Port, instance.Username, instance.Password, "mysql"))
+	return liveStatus(ctx, instance, probe), nil
 }
 
 func (e *MySQLEngine) List() ([]*types.Instance, error) {
@@ -0,0 +1,259 @@
+package engines
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// mysqlSystemSchemas are the databases a dump leaves out
+const mysqlSystemSchemas = `'mysql', 'information_schema', 'performance_schema', 'sys'`
+
+// mysqlBinaryTypes are the column types whose values are dumped as hex literals
+var mysqlBinaryTypes = map[string]bool{
+	"binary": true, "varbinary": true, "tinyblob": true, "blob": true, "mediumblob": true, "longblob": true,
+	"bit": true, "geometry": true, "point": true, "linestring": true, "polygon": true,
+	"multipoint": true, "multilinestring": true, "multipolygon": true, "geometrycollection": true,
+}
+
+// mysqlDefiner matches the DEFINER clause of SHOW CREATE VIEW, which names an
+// account the target server may not have
+var mysqlDefiner = regexp.MustCompile("DEFINER=`[^`]*`@`[^`]*` ")
+
+// mysqlIdent quotes an identifier
+func mysqlIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// mysqlValue quotes a value as a string literal, escaping the bytes mysqldump escapes
+func mysqlValue(value string) string {
+	var b strings.Builder
+	b.Grow(len(value) + 2)
+	b.WriteByte('\'')
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case 0x1a:
+			b.WriteString(`\Z`)
+		case '\\', '\'', '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// DumpFormats returns the formats MySQL dumps support
+func (e *MySQLEngine) DumpFormats() []string {
+	return []string{DumpFormatSQL}
+}
+
+// OfflineLoad reports whether a format is loaded while the instance is stopped
+func (e *MySQLEngine) OfflineLoad(format string) bool {
+	return false
+}
+
+// Dump writes the user databases of an instance as SQL statements. Stored
+// routines, triggers and events are not included.
+func (e *MySQLEngine) Dump(ctx context.Context, instanceID, format string, w io.Writer) error {
+	if format != DumpFormatSQL {
+		return unsupportedDumpFormat("mysql", format)
+	}
+
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	db, err := sql.Open("mysql", mysqlDSN(instance.Port, instance.Username, instance.Password, ""))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// A single snapshot keeps the dump consistent while the instance is in use
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer tx.Rollback()
+
+	out := newDumpWriter(w)
+	out.printf("-- instant-db dump of %s (MySQL %s)\n-- %s\n\n", instance.Name, instance.Version, time.Now().Format(time.RFC3339))
+	out.statement("SET NAMES utf8mb4")
+	out.statement("SET FOREIGN_KEY_CHECKS = 0")
+	out.statement("SET UNIQUE_CHECKS = 0")
+
+	if err := dumpMySQL(ctx, tx, out); err != nil {
+		return fmt.Errorf("failed to dump: %w", err)
+	}
+
+	out.printf("\n")
+	out.statement("SET FOREIGN_KEY_CHECKS = 1")
+	out.statement("SET UNIQUE_CHECKS = 1")
+	return out.close()
+}
+
+// dumpMySQL writes every user database with its tables and rows, and the
+// views of all databases at the end, once the tables they select from exist.
+// Like mysqldump, every table and view is dropped before it is created, so the
+// dump can be restored over the instance it came from.
+func dumpMySQL(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	databases, err := queryStrings(ctx, tx, `SELECT SCHEMA_NAME FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME NOT IN (`+mysqlSystemSchemas+`) ORDER BY SCHEMA_NAME`)
+	if err != nil {
+		return err
+	}
+
+	var views []string
+	for _, database := range databases {
+		name := mysqlIdent(database[0])
+		create, err := mysqlShowCreate(ctx, tx, "SHOW CREATE DATABASE IF NOT EXISTS "+name)
+		if err != nil {
+			return err
+		}
+		out.printf("\n")
+		out.statement(create)
+		out.statement("USE " + name)
+
+		tables, err := queryStrings(ctx, tx, `SELECT TABLE_NAME, TABLE_TYPE FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME`, database[0])
+		if err != nil {
+			return err
+		}
+
+		for _, table := range tables {
+			qualified := name + "." + mysqlIdent(table[0])
+			if table[1] == "VIEW" {
+				views = append(views, qualified)
+				continue
+			}
+
+			create, err := mysqlShowCreate(ctx, tx, "SHOW CREATE TABLE "+qualified)
+			if err != nil {
+				return err
+			}
+			out.printf("\n")
+			out.statement("DROP TABLE IF EXISTS " + mysqlIdent(table[0]))
+			out.statement(create)
+
+			if err := dumpMySQLRows(ctx, tx, out, database[0], table[0]); err != nil {
+				return fmt.Errorf("failed to read %s: %w", qualified, err)
+			}
+		}
+	}
+
+	for _, view := range views {
+		create, err := mysqlShowCreate(ctx, tx, "SHOW CREATE VIEW "+view)
+		if err != nil {
+			return err
+		}
+		out.printf("\n")
+		out.statement("DROP VIEW IF EXISTS " + view)
+		out.statement(mysqlDefiner.ReplaceAllString(create, ""))
+	}
+	return nil
+}
+
+// mysqlShowCreate runs a SHOW CREATE statement and returns the definition it shows
+func mysqlShowCreate(ctx context.Context, tx *sql.Tx, statement string) (string, error) {
+	rows, err := queryStrings(ctx, tx, statement)
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %w", statement, err)
+	}
+	if len(rows) == 0 || len(rows[0]) < 2 {
+		return "", fmt.Errorf("%s returned no definition", statement)
+	}
+	return rows[0][1], nil
+}
+
+// dumpMySQLRows writes the rows of a table as INSERT statements, leaving out
+// generated columns
+func dumpMySQLRows(ctx context.Context, tx *sql.Tx, out *dumpWriter, database, table string) error {
+	columns, err := queryStrings(ctx, tx, `SELECT COLUMN_NAME, DATA_TYPE, EXTRA FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, database, table)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	var binary []bool
+	for _, column := range columns {
+		if strings.Contains(column[2], "VIRTUAL GENERATED") || strings.Contains(column[2], "STORED GENERATED") {
+			continue
+		}
+		names = append(names, mysqlIdent(column[0]))
+		binary = append(binary, mysqlBinaryTypes[strings.ToLower(column[1])])
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	qualified := mysqlIdent(database) + "." + mysqlIdent(table)
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(names, ", "), qualified))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := insertBatcher{
+		out:    out,
+		prefix: fmt.Sprintf("INSERT INTO %s (%s) VALUES", mysqlIdent(table), strings.Join(names, ", ")),
+	}
+	values := make([]sql.NullString, len(names))
+	targets := make([]interface{}, len(names))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	literals := make([]string, len(names))
+	for rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			switch {
+			case !value.Valid:
+				literals[i] = "NULL"
+			case binary[i] && value.String == "":
+				literals[i] = "''"
+			case binary[i]:
+				literals[i] = "0x" + hex.EncodeToString([]byte(value.String))
+			default:
+				literals[i] = mysqlValue(value.String)
+			}
+		}
+		batch.add(literals)
+	}
+	batch.flush()
+	return rows.Err()
+}
+
+// Load runs a SQL dump against an instance
+func (e *MySQLEngine) Load(ctx context.Context, instanceID, format string, r io.Reader, progress func(done, total int)) error {
+	if format != DumpFormatSQL {
+		return unsupportedDumpFormat("mysql", format)
+	}
+
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	return loadSQLDump(ctx, "mysql", mysqlDSN(instance.Port, instance.Username, instance.Password, ""), r, progress)
+}
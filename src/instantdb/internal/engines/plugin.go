@@ -0,0 +1,431 @@
+package engines
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// PluginPrefix is the executable name prefix used to discover engine plugins on PATH.
+//
+// A plugin named instant-db-engine-<name> is invoked with the action as its only
+// argument (info, start, stop, pause, resume, status or url). The request is written
+// to stdin as JSON and the plugin answers with a single JSON object on stdout.
+const PluginPrefix = "instant-db-engine-"
+
+// pluginInfoTimeout bounds the info call made while discovering plugins
+const pluginInfoTimeout = 5 * time.Second
+
+// pluginRequest is the JSON document sent to a plugin on stdin
+type pluginRequest struct {
+	Action   string          `json:"action"`
+	Instance *pluginInstance `json:"instance,omitempty"`
+}
+
+// pluginInstance is the instance description shared with plugins
+type pluginInstance struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Port     int    `json:"port"`
+	DataDir  string `json:"data_dir"`
+	PID      int    `json:"pid,omitempty"`
+	Persist  bool   `json:"persist"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Version  string `json:"version,omitempty"`
+}
+
+// pluginResponse is the JSON document a plugin writes to stdout
+type pluginResponse struct {
+	Error  string        `json:"error,omitempty"`
+	Info   *pluginInfo   `json:"info,omitempty"`
+	PID    int           `json:"pid,omitempty"`
+	Status *pluginStatus `json:"status,omitempty"`
+	URL    string        `json:"url,omitempty"`
+}
+
+// pluginInfo describes the engine served by a plugin
+type pluginInfo struct {
+	DisplayName     string   `json:"display_name"`
+	Aliases         []string `json:"aliases"`
+	DefaultUsername string   `json:"default_username"`
+	DefaultPassword string   `json:"default_password"`
+}
+
+// pluginStatus is the status reported by a plugin
+type pluginStatus struct {
+	Running bool   `json:"running"`
+	Healthy bool   `json:"healthy"`
+	State   string `json:"state,omitempty"`
+	Message string `json:"message"`
+}
+
+// PluginEngine adapts an out-of-process engine plugin to the Engine interface
+type PluginEngine struct {
+	name    string
+	path    string
+	baseDir string
+}
+
+// NewPluginEngine creates an engine backed by the plugin executable at path
+func NewPluginEngine(name, path, baseDir string) *PluginEngine {
+	return &PluginEngine{
+		name:    name,
+		path:    path,
+		baseDir: baseDir,
+	}
+}
+
+// DiscoverPlugins scans PATH for engine plugins and registers them.
+// Built-in engines and plugins found earlier on PATH take precedence, and
+// files that are not executable are ignored. The info of each plugin is cached
+// in the instant-db home until its executable changes. Plugins that cannot
+// describe themselves are still registered with generic defaults; the returned
+// errors are meant to be shown as warnings.
+func DiscoverPlugins() []error {
+	var warnings []error
+	cache := loadPluginCache()
+	defer cache.save()
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasPrefix(name, PluginPrefix) {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				if !strings.EqualFold(filepath.Ext(name), ".exe") {
+					continue
+				}
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+
+			engineName := strings.ToLower(strings.TrimPrefix(name, PluginPrefix))
+			if engineName == "" {
+				continue
+			}
+			if _, err := Lookup(engineName); err == nil {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			stat, err := os.Stat(path)
+			if err != nil || stat.IsDir() || (runtime.GOOS != "windows" && stat.Mode()&0111 == 0) {
+				continue
+			}
+			if err := registerPlugin(engineName, path, stat, cache); err != nil {
+				warnings = append(warnings, err)
+			}
+		}
+	}
+
+	return warnings
+}
+
+// registerPlugin asks a plugin to describe itself, unless its info is cached,
+// and adds it to the registry
+func registerPlugin(name, path string, stat os.FileInfo, cache *pluginCache) error {
+	def := &Definition{
+		Name:        name,
+		DisplayName: name,
+		New: func(baseDir string) Engine {
+			return NewPluginEngine(name, path, baseDir)
+		},
+	}
+
+	info := cache.lookup(path, stat)
+	var err error
+	if info == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), pluginInfoTimeout)
+		defer cancel()
+
+		var resp *pluginResponse
+		plugin := NewPluginEngine(name, path, "")
+		if resp, err = plugin.call(ctx, "info", nil); err == nil && resp.Info != nil {
+			info = resp.Info
+			cache.store(path, stat, info)
+		}
+	}
+
+	if info != nil {
+		if info.DisplayName != "" {
+			def.DisplayName = info.DisplayName
+		}
+		def.DefaultUsername = info.DefaultUsername
+		def.DefaultPassword = info.DefaultPassword
+		seen := map[string]bool{name: true}
+		for _, alias := range info.Aliases {
+			alias = strings.ToLower(alias)
+			if _, lookupErr := Lookup(alias); lookupErr == nil || seen[alias] {
+				continue
+			}
+			seen[alias] = true
+			def.Aliases = append(def.Aliases, alias)
+		}
+	}
+
+	Register(def)
+
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", name, err)
+	}
+	return nil
+}
+
+// call runs a single plugin action and decodes its response
+func (e *PluginEngine) call(ctx context.Context, action string, instance *types.Instance) (*pluginResponse, error) {
+	req := pluginRequest{Action: action}
+	if instance != nil {
+		req.Instance = &pluginInstance{
+			ID:       instance.ID,
+			Name:     instance.Name,
+			Port:     instance.Port,
+			DataDir:  instance.DataDir,
+			PID:      instance.PID,
+			Persist:  instance.Persist,
+			Username: instance.Username,
+			Password: instance.Password,
+			Version:  instance.Version,
+		}
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.path, action)
+	cmd.Stdin = bytes.NewReader(payload)
+	if home, err := utils.HomeDir(); err == nil {
+		cmd.Env = append(os.Environ(), utils.HomeEnv+"="+home)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+
+	var resp pluginResponse
+	if out := bytes.TrimSpace(stdout.Bytes()); len(out) > 0 {
+		if err := json.Unmarshal(out, &resp); err != nil {
+			return nil, fmt.Errorf("invalid response from plugin %s: %w", e.name, err)
+		}
+	}
+
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	if runErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s %s failed: %s", e.name, action, msg)
+		}
+		return nil, fmt.Errorf("plugin %s %s failed: %w", e.name, action, runErr)
+	}
+
+	return &resp, nil
+}
+
+// Start starts a new instance through the plugin
+func (e *PluginEngine) Start(ctx context.Context, config types.Config) (*types.Instance, error) {
+	if config.WithData != "" {
+		return nil, fmt.Errorf("loading data at start is not supported for %s instances", e.name)
+	}
+
+	instanceID := utils.GenerateID()
+
+	if config.Name == "" {
+		config.Name = fmt.Sprintf("%s-%s", e.name, instanceID[:8])
+	}
+	if config.DataDir == "" {
+		config.DataDir = filepath.Join(e.baseDir, instanceID)
+	}
+
+	// Claim the name and port; the reservation is dropped if the start fails
+	instance, err := reserveInstance(instanceID, e.name, &config)
+	if err != nil {
+		return nil, err
+	}
+	started := false
+	defer func() {
+		if !started {
+			utils.RemoveInstance(instanceID)
+		}
+	}()
+
+	if err := os.MkdirAll(config.DataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	resp, err := e.call(ctx, "start", instance)
+	if err != nil {
+		os.RemoveAll(config.DataDir)
+		return nil, fmt.Errorf("failed to start %s: %w", e.name, err)
+	}
+	instance.PID = resp.PID
+	instance.StartedAt = time.Now().Unix()
+	instance.Status = "running"
+
+	if err := utils.SaveInstance(instance); err != nil {
+		e.call(ctx, "stop", instance)
+		os.RemoveAll(config.DataDir)
+		return nil, fmt.Errorf("failed to save instance: %w", err)
+	}
+
+	started = true
+	return instance, nil
+}
+
+// Stop stops an instance through the plugin and cleans up its data
+func (e *PluginEngine) Stop(ctx context.Context, instanceID string) error {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	if _, err := e.call(ctx, "stop", instance); err != nil {
+		return fmt.Errorf("failed to stop server: %w", err)
+	}
+
+	if !instance.Persist {
+		if err := os.RemoveAll(instance.DataDir); err != nil {
+			return fmt.Errorf("failed to remove data directory: %w", err)
+		}
+	}
+
+	if err := utils.RemoveInstance(instanceID); err != nil {
+		return fmt.Errorf("failed to remove instance metadata: %w", err)
+	}
+
+	return nil
+}
+
+// Pause pauses an instance through the plugin
+func (e *PluginEngine) Pause(ctx context.Context, instanceID string) error {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	if instance.Paused {
+		return fmt.Errorf("instance is already paused")
+	}
+
+	if _, err := e.call(ctx, "pause", instance); err != nil {
+		return fmt.Errorf("failed to pause server: %w", err)
+	}
+
+	return utils.UpdateInstance(instanceID, func(instance *types.Instance) error {
+		instance.Paused = true
+		instance.Status = "paused"
+		return nil
+	})
+}
+
+// Resume resumes a paused instance through the plugin
+func (e *PluginEngine) Resume(ctx context.Context, instanceID string) error {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	if !instance.Paused {
+		return fmt.Errorf("instance is not paused")
+	}
+
+	resp, err := e.call(ctx, "resume", instance)
+	if err != nil {
+		return fmt.Errorf("failed to resume %s: %w", e.name, err)
+	}
+
+	return utils.UpdateInstance(instanceID, func(instance *types.Instance) error {
+		instance.PID = resp.PID
+		instance.StartedAt = time.Now().Unix()
+		instance.Paused = false
+		instance.Status = "running"
+		return nil
+	})
+}
+
+// Status asks the plugin for the status of an instance
+func (e *PluginEngine) Status(ctx context.Context, instanceID string) (*types.Status, error) {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("instance not found: %w", err)
+	}
+
+	resp, err := e.call(ctx, "status", instance)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status == nil {
+		return nil, fmt.Errorf("plugin %s returned no status", e.name)
+	}
+
+	// Plugins that do not report a state get one derived from the running flag
+	state := resp.Status.State
+	if state == "" {
+		switch {
+		case resp.Status.Running:
+			state = types.StateRunning
+		case instance.Paused:
+			state = types.StatePaused
+		default:
+			state = types.StateCrashed
+		}
+	}
+
+	return &types.Status{
+		Running: resp.Status.Running,
+		Healthy: resp.Status.Healthy,
+		State:   state,
+		Message: resp.Status.Message,
+	}, nil
+}
+
+// GetConnectionURL asks the plugin for the connection URL of an instance
+func (e *PluginEngine) GetConnectionURL(instanceID string) (string, error) {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return "", fmt.Errorf("instance not found: %w", err)
+	}
+
+	resp, err := e.call(context.Background(), "url", instance)
+	if err != nil {
+		return "", err
+	}
+	if resp.URL == "" {
+		return "", fmt.Errorf("plugin %s returned no connection URL", e.name)
+	}
+
+	return resp.URL, nil
+}
+
+// List returns all instances managed by this plugin
+func (e *PluginEngine) List() ([]*types.Instance, error) {
+	all, err := utils.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+	var instances []*types.Instance
+	for _, inst := range all {
+		if inst.Engine == e.name {
+			instances = append(instances, inst)
+		}
+	}
+	return instances, nil
+}
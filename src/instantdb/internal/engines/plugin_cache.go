@@ -0,0 +1,92 @@
+package engines
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// pluginCacheFile holds the info of discovered plugins in the cache directory of
+// the instant-db home, so plugins are not run on every invocation. It is kept
+// out of the home itself, where every JSON file is an instance record.
+const pluginCacheFile = "plugins.json"
+
+// pluginCacheEntry is the info a plugin returned, valid while its executable is unchanged
+type pluginCacheEntry struct {
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"mod_time"`
+	Info    *pluginInfo `json:"info"`
+}
+
+// pluginCache maps plugin executable paths to their cached info
+type pluginCache struct {
+	entries map[string]pluginCacheEntry
+	seen    map[string]bool
+	changed bool
+}
+
+// loadPluginCache reads the plugin cache. A missing or unreadable cache is empty.
+func loadPluginCache() *pluginCache {
+	cache := &pluginCache{entries: map[string]pluginCacheEntry{}, seen: map[string]bool{}}
+	if path, err := pluginCachePath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &cache.entries)
+		}
+	}
+	return cache
+}
+
+// lookup returns the cached info of a plugin if its executable has not changed
+func (c *pluginCache) lookup(path string, stat os.FileInfo) *pluginInfo {
+	c.seen[path] = true
+	entry, ok := c.entries[path]
+	if !ok || entry.Size != stat.Size() || !entry.ModTime.Equal(stat.ModTime()) {
+		return nil
+	}
+	return entry.Info
+}
+
+// store caches the info of a plugin executable
+func (c *pluginCache) store(path string, stat os.FileInfo, info *pluginInfo) {
+	c.entries[path] = pluginCacheEntry{Size: stat.Size(), ModTime: stat.ModTime(), Info: info}
+	c.changed = true
+}
+
+// save drops plugins that are no longer on PATH and writes the cache if it
+// changed. Failing to save only means plugins are asked again next time.
+func (c *pluginCache) save() {
+	for path := range c.entries {
+		if !c.seen[path] {
+			delete(c.entries, path)
+			c.changed = true
+		}
+	}
+	if !c.changed {
+		return
+	}
+
+	path, err := pluginCachePath()
+	if err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	utils.WriteFileAtomic(path, data)
+}
+
+// pluginCachePath returns the path of the plugin cache
+func pluginCachePath() (string, error) {
+	home, err := utils.HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "cache", pluginCacheFile), nil
+}
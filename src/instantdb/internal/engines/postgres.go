@@ -2,9 +2,14 @@ package engines
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
@@ -12,18 +17,133 @@ import (
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
 )
 
+func init() {
+	Register(&Definition{
+		Name:            "postgres",
+		Aliases:         []string{"postgresql", "pg"},
+		DisplayName:     "PostgreSQL",
+		DefaultUsername: "postgres",
+		DefaultPassword: "postgres",
+		DefaultVersion:  postgresDefaultVersion,
+		Interactive:     true,
+		URLEnv:          "DATABASE_URL",
+		EnvPrefix:       "PG",
+		ProcessNames:    []string{"postgres"},
+		SQL:             true,
+		LogFile:         "postgres.log",
+		New: func(baseDir string) Engine {
+			return NewPostgresEngine(baseDir)
+		},
+	})
+}
+
+// postgresDefaultVersion is the PostgreSQL release used when no version is requested
+const postgresDefaultVersion = string(embeddedpostgres.V15)
+
+// postgresReleases maps major versions to the releases embedded-postgres provides
+var postgresReleases = map[string]embeddedpostgres.PostgresVersion{
+	"15": embeddedpostgres.V15,
+	"14": embeddedpostgres.V14,
+	"13": embeddedpostgres.V13,
+	"12": embeddedpostgres.V12,
+	"11": embeddedpostgres.V11,
+	"10": embeddedpostgres.V10,
+	"9":  embeddedpostgres.V9,
+}
+
 // PostgresEngine implements the Engine interface for PostgreSQL
 type PostgresEngine struct {
-	baseDir   string
+	baseDir    string
+	cacheDir   string
+	runtimeDir string
+	mirror     string
+
+	// mu guards instances, which may be started concurrently
+	mu        sync.Mutex
 	instances map[string]*embeddedpostgres.EmbeddedPostgres
 }
 
 // NewPostgresEngine creates a new PostgreSQL engine
 func NewPostgresEngine(baseDir string) *PostgresEngine {
+	cacheDir, _ := utils.BinaryDir("postgres", ".embedded-postgres-go")
+	homeDir, _ := utils.HomeDir()
 	return &PostgresEngine{
-		baseDir:   baseDir,
-		instances: make(map[string]*embeddedpostgres.EmbeddedPostgres),
+		baseDir:    baseDir,
+		cacheDir:   cacheDir,
+		runtimeDir: filepath.Join(homeDir, "runtime", "postgres"),
+		instances:  make(map[string]*embeddedpostgres.EmbeddedPostgres),
+	}
+}
+
+// SetMirror replaces the Maven repository PostgreSQL binaries are downloaded from
+func (e *PostgresEngine) SetMirror(url string) {
+	e.mirror = url
+}
+
+// postgresVersion resolves a requested version such as "14" to a full release
+func postgresVersion(version string) string {
+	if version == "" {
+		return postgresDefaultVersion
+	}
+	if release, ok := postgresReleases[version]; ok {
+		return string(release)
 	}
+	return version
+}
+
+// baseConfig returns the embedded-postgres configuration of an instance. Each
+// release is extracted once and shared; the runtime directory, which the
+// library wipes on every start, is private to the instance.
+func (e *PostgresEngine) baseConfig(version, instanceID string) embeddedpostgres.Config {
+	version = postgresVersion(version)
+	config := embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.PostgresVersion(version)).
+		CachePath(e.cacheDir).
+		BinariesPath(filepath.Join(e.runtimeDir, version)).
+		RuntimePath(e.instanceRuntimeDir(instanceID))
+	if e.mirror != "" {
+		config = config.BinaryRepositoryURL(e.mirror)
+	}
+	return config
+}
+
+// withLogging sends the server log, and the output of initdb and pg_ctl, to
+// postgres.log in the data directory instead of a temporary file
+func withLogging(config embeddedpostgres.Config, dataDir string) embeddedpostgres.Config {
+	logFile := filepath.Join(dataDir, "postgres.log")
+	RotateLog(logFile)
+
+	return config.
+		Logger(logFileWriter(logFile)).
+		StartParameters(map[string]string{
+			"logging_collector": "on",
+			"log_directory":     dataDir,
+			"log_filename":      "postgres.log",
+			"log_rotation_age":  "0",
+			"log_rotation_size": "0",
+			"log_timezone":      "UTC",
+		})
+}
+
+// instanceRuntimeDir returns the scratch directory embedded-postgres uses for an instance
+func (e *PostgresEngine) instanceRuntimeDir(instanceID string) string {
+	return filepath.Join(e.runtimeDir, "instances", instanceID)
+}
+
+// track stores the in-process reference of a started server
+func (e *PostgresEngine) track(instanceID string, postgres *embeddedpostgres.EmbeddedPostgres) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.instances[instanceID] = postgres
+}
+
+// untrack removes and returns the in-process reference of a server, if any
+func (e *PostgresEngine) untrack(instanceID string) (*embeddedpostgres.EmbeddedPostgres, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	postgres, exists := e.instances[instanceID]
+	delete(e.instances, instanceID)
+	return postgres, exists
 }
 
 // Start starts a new PostgreSQL instance
@@ -35,13 +155,6 @@ func (e *PostgresEngine) Start(ctx context.Context, config types.Config) (*types
 	if config.Name == "" {
 		config.Name = fmt.Sprintf("postgres-%s", instanceID[:8])
 	}
-	if config.Port == 0 {
-		port, err := utils.GetFreePort()
-		if err != nil {
-			return nil, fmt.Errorf("failed to allocate port: %w", err)
-		}
-		config.Port = port
-	}
 	if config.DataDir == "" {
 		config.DataDir = filepath.Join(e.baseDir, instanceID)
 	}
@@ -51,6 +164,19 @@ func (e *PostgresEngine) Start(ctx context.Context, config types.Config) (*types
 	if config.Password == "" {
 		config.Password = "postgres"
 	}
+	config.Version = postgresVersion(config.Version)
+
+	// Claim the name and port; the reservation is dropped if the start fails
+	instance, err := reserveInstance(instanceID, "postgres", &config)
+	if err != nil {
+		return nil, err
+	}
+	started := false
+	defer func() {
+		if !started {
+			utils.RemoveInstance(instanceID)
+		}
+	}()
 
 	// Create data directory
 	if err := os.MkdirAll(config.DataDir, 0755); err != nil {
@@ -58,48 +184,66 @@ func (e *PostgresEngine) Start(ctx context.Context, config types.Config) (*types
 	}
 
 	// Create embedded postgres instance
-	// Binaries will be downloaded automatically to ~/.embedded-postgres-go/
+	// Binaries are downloaded automatically to the engine's binary cache
 	postgres := embeddedpostgres.NewDatabase(
-		embeddedpostgres.DefaultConfig().
+		withLogging(e.baseConfig(config.Version, instanceID), config.DataDir).
 			Port(uint32(config.Port)).
 			Username(config.Username).
 			Password(config.Password).
 			DataPath(config.DataDir).
-			RuntimePath(filepath.Join(os.TempDir(), "embedded-pg-runtime")).
-			StartTimeout(30 * time.Second),
+			StartTimeout(readyTimeout(config.ReadyTimeout)),
 	)
 
 	// Start PostgreSQL
+	logFile := filepath.Join(config.DataDir, "postgres.log")
 	if err := postgres.Start(); err != nil {
+		startErr := newStartError(fmt.Errorf("failed to start postgres: %w", err), logFile)
 		os.RemoveAll(config.DataDir)
-		return nil, fmt.Errorf("failed to start postgres: %w", err)
+		return nil, startErr
 	}
 
-	// Store instance reference
-	e.instances[instanceID] = postgres
+	probe := sqlProbe("postgres", postgresDSN(config.Port, config.Username, config.Password, "postgres"))
+	if err := waitForReady(ctx, config.ReadyTimeout, probe, nil); err != nil {
+		postgres.Stop()
+		startErr := newStartError(fmt.Errorf("postgres failed to start: %w", err), logFile)
+		os.RemoveAll(config.DataDir)
+		return nil, startErr
+	}
+
+	pid, err := postmasterPID(config.DataDir)
+	if err != nil {
+		postgres.Stop()
+		os.RemoveAll(config.DataDir)
+		return nil, err
+	}
 
-	// Create instance metadata
-	instance := &types.Instance{
-		ID:        instanceID,
-		Name:      config.Name,
-		Engine:    "postgres",
-		Port:      config.Port,
-		DataDir:   config.DataDir,
-		PID:       0, // embedded-postgres manages the process
-		Status:    "running",
-		CreatedAt: time.Now().Unix(),
-		Persist:   config.Persist,
-		Username:  config.Username,
-		Password:  config.Password,
+	// Load seed data into the ready server
+	if config.WithData != "" {
+		if err := loadSQLSeed(ctx, "postgres", postgresDSN(config.Port, config.Username, config.Password, "postgres"), config.WithData); err != nil {
+			postgres.Stop()
+			os.RemoveAll(config.DataDir)
+			return nil, err
+		}
 	}
 
+	// Store instance reference
+	e.track(instanceID, postgres)
+
+	// Record the running server
+	instance.PID = pid
+	instance.PGID = utils.ProcessGroup(pid)
+	instance.StartedAt = time.Now().Unix()
+	instance.Status = "running"
+
 	// Save instance metadata
 	if err := utils.SaveInstance(instance); err != nil {
 		postgres.Stop()
+		e.untrack(instanceID)
 		os.RemoveAll(config.DataDir)
 		return nil, fmt.Errorf("failed to save instance: %w", err)
 	}
 
+	started = true
 	return instance, nil
 }
 
@@ -110,13 +254,15 @@ func (e *PostgresEngine) Stop(ctx context.Context, instanceID string) error {
 		return fmt.Errorf("instance not found: %w", err)
 	}
 
-	// Stop the postgres instance if we have a reference
-	if postgres, exists := e.instances[instanceID]; exists {
+	// Stop the postgres instance through our reference, or by its recorded process
+	if postgres, exists := e.untrack(instanceID); exists {
 		if err := postgres.Stop(); err != nil {
 			return fmt.Errorf("failed to stop server: %w", err)
 		}
-		delete(e.instances, instanceID)
+	} else if err := stopPostmaster(instance); err != nil {
+		return err
 	}
+	os.RemoveAll(e.instanceRuntimeDir(instanceID))
 
 	// Clean up data directory if not persistent
 	if !instance.Persist {
@@ -145,22 +291,23 @@ func (e *PostgresEngine) Pause(ctx context.Context, instanceID string) error {
 	}
 
 	// Stop the postgres instance if we have a reference
-	if postgres, exists := e.instances[instanceID]; exists {
+	if postgres, exists := e.untrack(instanceID); exists {
 		if err := postgres.Stop(); err != nil {
 			return fmt.Errorf("failed to pause server: %w", err)
 		}
-		delete(e.instances, instanceID)
-	} else {
-		// No in-memory reference - find and kill postgres process on this port
-		if err := utils.KillProcessOnPort(instance.Port); err != nil {
-			return fmt.Errorf("failed to stop postgres process: %w", err)
-		}
+	} else if err := stopPostmaster(instance); err != nil {
+		return err
 	}
 
 	// Mark as paused and save
-	instance.Paused = true
-	instance.Status = "paused"
-	if err := utils.SaveInstance(instance); err != nil {
+	err = utils.UpdateInstance(instanceID, func(instance *types.Instance) error {
+		instance.PID = 0
+		instance.PGID = 0
+		instance.Paused = true
+		instance.Status = "paused"
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to save instance: %w", err)
 	}
 
@@ -180,28 +327,47 @@ func (e *PostgresEngine) Resume(ctx context.Context, instanceID string) error {
 
 	// Create embedded postgres instance
 	postgres := embeddedpostgres.NewDatabase(
-		embeddedpostgres.DefaultConfig().
+		withLogging(e.baseConfig(instance.Version, instanceID), instance.DataDir).
 			Port(uint32(instance.Port)).
 			Username(instance.Username).
 			Password(instance.Password).
 			DataPath(instance.DataDir).
-			RuntimePath(filepath.Join(os.TempDir(), "embedded-pg-runtime")).
-			StartTimeout(30 * time.Second),
+			StartTimeout(readyTimeout(instance.ReadyTimeout)),
 	)
 
 	// Start PostgreSQL
+	logFile := filepath.Join(instance.DataDir, "postgres.log")
 	if err := postgres.Start(); err != nil {
-		return fmt.Errorf("failed to resume postgres: %w", err)
+		return newStartError(fmt.Errorf("failed to resume postgres: %w", err), logFile)
+	}
+
+	probe := sqlProbe("postgres", postgresDSN(instance.Port, instance.Username, instance.Password, "postgres"))
+	if err := waitForReady(ctx, instance.ReadyTimeout, probe, nil); err != nil {
+		postgres.Stop()
+		return newStartError(fmt.Errorf("postgres failed to resume: %w", err), logFile)
+	}
+
+	pid, err := postmasterPID(instance.DataDir)
+	if err != nil {
+		postgres.Stop()
+		return err
 	}
 
 	// Store instance reference
-	e.instances[instanceID] = postgres
+	e.track(instanceID, postgres)
 
 	// Mark as running and save
-	instance.Paused = false
-	instance.Status = "running"
-	if err := utils.SaveInstance(instance); err != nil {
+	err = utils.UpdateInstance(instanceID, func(instance *types.Instance) error {
+		instance.PID = pid
+		instance.PGID = utils.ProcessGroup(pid)
+		instance.StartedAt = time.Now().Unix()
+		instance.Paused = false
+		instance.Status = "running"
+		return nil
+	})
+	if err != nil {
 		postgres.Stop()
+		e.untrack(instanceID)
 		return fmt.Errorf("failed to save instance: %w", err)
 	}
 
@@ -219,23 +385,19 @@ func (e *PostgresEngine) Status(ctx context.Context, instanceID string) (*types.
 		}, nil
 	}
 
-	// Check if we have an active reference
-	_, exists := e.instances[instanceID]
-	
-	// Check if data directory exists
-	if _, err := os.Stat(instance.DataDir); os.IsNotExist(err) {
-		return &types.Status{
-			Running: false,
-			Healthy: false,
-			Message: "data directory not found",
-		}, nil
+	probe := sqlProbe("postgres", postgresDSN(instance.Port, instance.Username, instance.Password, "postgres"))
+	return liveStatus(ctx, instance, probe), nil
+}
+
+// Seed runs a SQL file, a .sql.gz file or a directory of them against the
+// postgres database of an instance
+func (e *PostgresEngine) Seed(ctx context.Context, instanceID, path string) error {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
 	}
 
-	return &types.Status{
-		Running: exists,
-		Healthy: exists,
-		Message: "ok",
-	}, nil
+	return loadSQLSeed(ctx, "postgres", postgresDSN(instance.Port, instance.Username, instance.Password, "postgres"), path)
 }
 
 // GetConnectionURL returns the connection URL for an instance
@@ -245,8 +407,9 @@ func (e *PostgresEngine) GetConnectionURL(instanceID string) (string, error) {
 		return "", fmt.Errorf("instance not found: %w", err)
 	}
 
-	return fmt.Sprintf("postgresql://%s:%s@localhost:%d/postgres?sslmode=disable", 
-		instance.Username, instance.Password, instance.Port), nil
+	// Credentials are escaped so the URL stays parseable
+	return fmt.Sprintf("postgresql://%s@localhost:%d/postgres?sslmode=disable",
+		url.UserPassword(instance.Username, instance.Password), instance.Port), nil
 }
 
 // List returns all running PostgreSQL instances
@@ -266,3 +429,35 @@ func (e *PostgresEngine) List() ([]*types.Instance, error) {
 
 	return postgresInstances, nil
 }
+
+// postmasterPID reads the server PID from the postmaster.pid file in a data directory
+func postmasterPID(dataDir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, "postmaster.pid"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read postmaster.pid: %w", err)
+	}
+
+	line, _, _ := strings.Cut(string(data), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		return 0, fmt.Errorf("invalid postmaster.pid: %w", err)
+	}
+	return pid, nil
+}
+
+// stopPostmaster stops a server started by another instant-db process.
+// Instances recorded before PIDs were tracked fall back to postmaster.pid,
+// which postgres removes when it shuts down.
+func stopPostmaster(instance *types.Instance) error {
+	if instance.PID == 0 {
+		pid, err := postmasterPID(instance.DataDir)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find the postgres process: %w", err)
+		}
+		instance.PID = pid
+	}
+	return stopProcess(instance, "postgres")
+}
@@ -0,0 +1,593 @@
+package engines
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/lib/pq"
+)
+
+// pgUserSchemas restricts catalog queries joined to pg_namespace n to user schemas
+const pgUserSchemas = `n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg\_%'`
+
+// pgNotExtensionMember excludes objects created by an extension, which
+// CREATE EXTENSION brings back on its own
+func pgNotExtensionMember(catalog, oid string) string {
+	return fmt.Sprintf("NOT EXISTS (SELECT 1 FROM pg_depend e WHERE e.classid = '%s'::regclass AND e.objid = %s AND e.deptype = 'e')", catalog, oid)
+}
+
+// pgQualified quotes a schema-qualified name
+func pgQualified(schema, name string) string {
+	return pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(name)
+}
+
+// pgColumn is a column of a dumped table
+type pgColumn struct {
+	name      string
+	dataType  string
+	notNull   bool
+	def       string
+	identity  string
+	generated string
+}
+
+// DumpFormats returns the formats PostgreSQL dumps support
+func (e *PostgresEngine) DumpFormats() []string {
+	return []string{DumpFormatSQL}
+}
+
+// OfflineLoad reports whether a format is loaded while the instance is stopped
+func (e *PostgresEngine) OfflineLoad(format string) bool {
+	return false
+}
+
+// Dump writes the postgres database of an instance as SQL statements
+func (e *PostgresEngine) Dump(ctx context.Context, instanceID, format string, w io.Writer) error {
+	if format != DumpFormatSQL {
+		return unsupportedDumpFormat("postgres", format)
+	}
+
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	db, err := sql.Open("postgres", postgresDSN(instance.Port, instance.Username, instance.Password, "postgres"))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// A single snapshot keeps the dump consistent while the instance is in use
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer tx.Rollback()
+
+	out := newDumpWriter(w)
+	out.printf("-- instant-db dump of %s (PostgreSQL %s)\n-- %s\n\n", instance.Name, instance.Version, time.Now().Format(time.RFC3339))
+	out.statement("SET check_function_bodies = false")
+	out.statement("SET client_min_messages = warning")
+
+	if err := dumpPostgres(ctx, tx, out); err != nil {
+		return fmt.Errorf("failed to dump: %w", err)
+	}
+	return out.close()
+}
+
+// dumpPostgres writes the schema and data of a database in dependency order
+func dumpPostgres(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	steps := []func(context.Context, *sql.Tx, *dumpWriter) error{
+		checkPostgresDumpable,
+		dumpPostgresClean,
+		dumpPostgresExtensions,
+		dumpPostgresSchemas,
+		dumpPostgresEnums,
+		dumpPostgresDomains,
+		dumpPostgresCompositeTypes,
+		dumpPostgresSequences,
+		dumpPostgresFunctions,
+		dumpPostgresTables,
+		dumpPostgresSequenceValues,
+		dumpPostgresViews,
+		dumpPostgresConstraints(false),
+		dumpPostgresIndexes,
+		dumpPostgresConstraints(true),
+		dumpPostgresTriggers,
+	}
+	for _, step := range steps {
+		if err := step(ctx, tx, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPostgresDumpable refuses databases with partitioned tables, whose data
+// the dump would otherwise leave out
+func checkPostgresDumpable(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT n.nspname, c.relname FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind = 'p' AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_class", "c.oid")+`
+		ORDER BY 1, 2`)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(rows))
+	for i, row := range rows {
+		names[i] = pgQualified(row[0], row[1])
+	}
+	return fmt.Errorf("partitioned tables are not supported, use pg_dump instead: %s", strings.Join(names, ", "))
+}
+
+// dumpPostgresClean drops the views, tables, sequences, types and domains the
+// dump creates, dependents first, so it can be restored over the instance it came
+// from. Each kind is dropped in one statement, which allows references between
+// the objects dropped.
+func dumpPostgresClean(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	drops := []struct {
+		kind  string
+		query string
+	}{
+		{"MATERIALIZED VIEW", `SELECT n.nspname, c.relname FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind = 'm' AND ` + pgUserSchemas + ` AND ` + pgNotExtensionMember("pg_class", "c.oid") + `
+			ORDER BY 1, 2`},
+		{"VIEW", `SELECT n.nspname, c.relname FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind = 'v' AND ` + pgUserSchemas + ` AND ` + pgNotExtensionMember("pg_class", "c.oid") + `
+			ORDER BY 1, 2`},
+		{"TABLE", `SELECT n.nspname, c.relname FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind = 'r' AND NOT c.relispartition AND ` + pgUserSchemas + ` AND ` + pgNotExtensionMember("pg_class", "c.oid") + `
+			ORDER BY 1, 2`},
+		{"SEQUENCE", `SELECT n.nspname, c.relname FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE c.relkind = 'S' AND ` + pgUserSchemas + ` AND ` + pgNotExtensionMember("pg_class", "c.oid") + `
+			AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_class'::regclass AND d.objid = c.oid AND d.deptype = 'i')
+			ORDER BY 1, 2`},
+		{"TYPE", `SELECT n.nspname, t.typname FROM pg_type t
+			JOIN pg_namespace n ON n.oid = t.typnamespace
+			JOIN pg_class c ON c.oid = t.typrelid
+			WHERE t.typtype = 'c' AND c.relkind = 'c' AND ` + pgUserSchemas + ` AND ` + pgNotExtensionMember("pg_type", "t.oid") + `
+			ORDER BY 1, 2`},
+		{"DOMAIN", `SELECT n.nspname, t.typname FROM pg_type t
+			JOIN pg_namespace n ON n.oid = t.typnamespace
+			WHERE t.typtype = 'd' AND ` + pgUserSchemas + ` AND ` + pgNotExtensionMember("pg_type", "t.oid") + `
+			ORDER BY 1, 2`},
+		{"TYPE", `SELECT n.nspname, t.typname FROM pg_type t
+			JOIN pg_namespace n ON n.oid = t.typnamespace
+			WHERE t.typtype = 'e' AND ` + pgUserSchemas + ` AND ` + pgNotExtensionMember("pg_type", "t.oid") + `
+			ORDER BY 1, 2`},
+	}
+
+	for _, drop := range drops {
+		rows, err := queryStrings(ctx, tx, drop.query)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		names := make([]string, len(rows))
+		for i, row := range rows {
+			names[i] = pgQualified(row[0], row[1])
+		}
+		out.statement(fmt.Sprintf("DROP %s IF EXISTS %s", drop.kind, strings.Join(names, ", ")))
+	}
+	out.printf("\n")
+	return nil
+}
+
+func dumpPostgresExtensions(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT extname FROM pg_extension WHERE extname <> 'plpgsql' ORDER BY extname`)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		out.statement("CREATE EXTENSION IF NOT EXISTS " + pq.QuoteIdentifier(row[0]))
+	}
+	return nil
+}
+
+func dumpPostgresSchemas(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT n.nspname FROM pg_namespace n
+		WHERE `+pgUserSchemas+` AND n.nspname <> 'public' AND `+pgNotExtensionMember("pg_namespace", "n.oid")+`
+		ORDER BY n.nspname`)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		out.statement("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(row[0]))
+	}
+	return nil
+}
+
+func dumpPostgresEnums(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT n.nspname, t.typname,
+			string_agg(quote_literal(e.enumlabel), ', ' ORDER BY e.enumsortorder)
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_enum e ON e.enumtypid = t.oid
+		WHERE `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_type", "t.oid")+`
+		GROUP BY n.nspname, t.typname
+		ORDER BY 1, 2`)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		out.statement(fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", pgQualified(row[0], row[1]), row[2]))
+	}
+	return nil
+}
+
+// dumpPostgresDomains creates domains with their defaults and check constraints
+func dumpPostgresDomains(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT n.nspname, t.typname, format_type(t.typbasetype, t.typtypmod),
+			COALESCE(t.typdefault, ''), t.typnotnull,
+			COALESCE((SELECT string_agg(' CONSTRAINT ' || quote_ident(con.conname) || ' ' || pg_get_constraintdef(con.oid), '' ORDER BY con.conname)
+				FROM pg_constraint con WHERE con.contypid = t.oid AND con.contype = 'c'), '')
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		WHERE t.typtype = 'd' AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_type", "t.oid")+`
+		ORDER BY t.oid`)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		definition := fmt.Sprintf("CREATE DOMAIN %s AS %s", pgQualified(row[0], row[1]), row[2])
+		if row[3] != "" {
+			definition += " DEFAULT " + row[3]
+		}
+		if row[4] == "true" {
+			definition += " NOT NULL"
+		}
+		out.statement(definition + row[5])
+	}
+	return nil
+}
+
+// dumpPostgresCompositeTypes creates composite types made with CREATE TYPE ... AS
+func dumpPostgresCompositeTypes(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT n.nspname, t.typname,
+			string_agg(quote_ident(a.attname) || ' ' || format_type(a.atttypid, a.atttypmod), ', ' ORDER BY a.attnum)
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_class c ON c.oid = t.typrelid
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+		WHERE t.typtype = 'c' AND c.relkind = 'c' AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_type", "t.oid")+`
+		GROUP BY t.oid, n.nspname, t.typname
+		ORDER BY t.oid`)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		out.statement(fmt.Sprintf("CREATE TYPE %s AS (%s)", pgQualified(row[0], row[1]), row[2]))
+	}
+	return nil
+}
+
+// dumpPostgresSequences creates sequences that are not part of an identity
+// column, before the tables whose defaults use them
+func dumpPostgresSequences(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT n.nspname, c.relname, format_type(s.seqtypid, NULL),
+			s.seqincrement, s.seqmin, s.seqmax, s.seqstart, s.seqcycle
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_sequence s ON s.seqrelid = c.oid
+		WHERE c.relkind = 'S' AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_class", "c.oid")+`
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_class'::regclass AND d.objid = c.oid AND d.deptype = 'i')
+		ORDER BY 1, 2`)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		cycle := "NO CYCLE"
+		if row[7] == "true" {
+			cycle = "CYCLE"
+		}
+		out.statement(fmt.Sprintf("CREATE SEQUENCE %s AS %s INCREMENT BY %s MINVALUE %s MAXVALUE %s START WITH %s %s",
+			pgQualified(row[0], row[1]), row[2], row[3], row[4], row[5], row[6], cycle))
+	}
+	return nil
+}
+
+func dumpPostgresFunctions(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT pg_get_functiondef(p.oid)
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE p.prokind IN ('f', 'p') AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_proc", "p.oid")+`
+		ORDER BY p.oid`)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		out.statement(row[0])
+	}
+	return nil
+}
+
+// dumpPostgresTables creates every table and writes its rows
+func dumpPostgresTables(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	tables, err := queryStrings(ctx, tx, `SELECT c.oid, n.nspname, c.relname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind = 'r' AND NOT c.relispartition AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_class", "c.oid")+`
+		ORDER BY 2, 3`)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		name := pgQualified(table[1], table[2])
+		columns, err := postgresColumns(ctx, tx, table[0])
+		if err != nil {
+			return err
+		}
+
+		definitions := make([]string, len(columns))
+		for i, column := range columns {
+			definition := pq.QuoteIdentifier(column.name) + " " + column.dataType
+			switch {
+			case column.generated == "s":
+				definition += fmt.Sprintf(" GENERATED ALWAYS AS (%s) STORED", column.def)
+			case column.identity == "a":
+				definition += " GENERATED ALWAYS AS IDENTITY"
+			case column.identity == "d":
+				definition += " GENERATED BY DEFAULT AS IDENTITY"
+			case column.def != "":
+				definition += " DEFAULT " + column.def
+			}
+			if column.notNull {
+				definition += " NOT NULL"
+			}
+			definitions[i] = definition
+		}
+		out.printf("\n")
+		out.statement(fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", name, strings.Join(definitions, ",\n  ")))
+
+		if err := dumpPostgresRows(ctx, tx, out, name, columns); err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// postgresColumns returns the columns of a table in order
+func postgresColumns(ctx context.Context, tx *sql.Tx, tableOID string) ([]pgColumn, error) {
+	rows, err := queryStrings(ctx, tx, `SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull,
+			pg_get_expr(d.adbin, d.adrelid), a.attidentity, a.attgenerated
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, tableOID)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]pgColumn, len(rows))
+	for i, row := range rows {
+		columns[i] = pgColumn{
+			name:      row[0],
+			dataType:  row[1],
+			notNull:   row[2] == "true",
+			def:       row[3],
+			identity:  row[4],
+			generated: row[5],
+		}
+	}
+	return columns, nil
+}
+
+// dumpPostgresRows writes the rows of a table as INSERT statements. Values
+// are read as text, which every type can be loaded back from.
+func dumpPostgresRows(ctx context.Context, tx *sql.Tx, out *dumpWriter, table string, columns []pgColumn) error {
+	var names, selects []string
+	overriding := ""
+	for _, column := range columns {
+		if column.generated != "" {
+			continue
+		}
+		names = append(names, pq.QuoteIdentifier(column.name))
+		selects = append(selects, pq.QuoteIdentifier(column.name)+"::text")
+		if column.identity == "a" {
+			overriding = " OVERRIDING SYSTEM VALUE"
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := insertBatcher{
+		out:    out,
+		prefix: fmt.Sprintf("INSERT INTO %s (%s)%s VALUES", table, strings.Join(names, ", "), overriding),
+	}
+	values := make([]sql.NullString, len(names))
+	targets := make([]interface{}, len(names))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	literals := make([]string, len(names))
+	for rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			if value.Valid {
+				literals[i] = pq.QuoteLiteral(value.String)
+			} else {
+				literals[i] = "NULL"
+			}
+		}
+		batch.add(literals)
+	}
+	batch.flush()
+	return rows.Err()
+}
+
+// dumpPostgresSequenceValues ties serial sequences to their columns and
+// restores the position of every sequence
+func dumpPostgresSequenceValues(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	sequences, err := queryStrings(ctx, tx, `SELECT n.nspname, c.relname, COALESCE(d.deptype::text, ''),
+			COALESCE(tn.nspname, ''), COALESCE(t.relname, ''), COALESCE(a.attname, '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_depend d ON d.classid = 'pg_class'::regclass AND d.objid = c.oid
+			AND d.refclassid = 'pg_class'::regclass AND d.deptype IN ('a', 'i')
+		LEFT JOIN pg_class t ON t.oid = d.refobjid
+		LEFT JOIN pg_namespace tn ON tn.oid = t.relnamespace
+		LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE c.relkind = 'S' AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_class", "c.oid")+`
+		ORDER BY 1, 2`)
+	if err != nil {
+		return err
+	}
+
+	if len(sequences) > 0 {
+		out.printf("\n")
+	}
+	for _, sequence := range sequences {
+		name := pgQualified(sequence[0], sequence[1])
+
+		var lastValue, isCalled string
+		err := tx.QueryRowContext(ctx, "SELECT last_value::text, is_called::text FROM "+name).Scan(&lastValue, &isCalled)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		// Identity sequences are recreated with their column, under a name of
+		// the server's choosing, so they are addressed through the column
+		target := pq.QuoteLiteral(name)
+		if sequence[2] != "" {
+			table := pgQualified(sequence[3], sequence[4])
+			if sequence[2] == "a" {
+				out.statement(fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.%s", name, table, pq.QuoteIdentifier(sequence[5])))
+			}
+			target = fmt.Sprintf("pg_get_serial_sequence(%s, %s)", pq.QuoteLiteral(table), pq.QuoteLiteral(sequence[5]))
+		}
+		out.statement(fmt.Sprintf("SELECT pg_catalog.setval(%s, %s, %s)", target, lastValue, isCalled))
+	}
+	return nil
+}
+
+func dumpPostgresViews(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT n.nspname, c.relname, c.relkind::text, pg_get_viewdef(c.oid)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('v', 'm') AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_class", "c.oid")+`
+		ORDER BY c.oid`)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		kind := "VIEW"
+		if row[2] == "m" {
+			kind = "MATERIALIZED VIEW"
+		}
+		out.printf("\n")
+		out.statement(fmt.Sprintf("CREATE %s %s AS\n%s", kind, pgQualified(row[0], row[1]), row[3]))
+	}
+	return nil
+}
+
+// dumpPostgresConstraints adds table constraints after the data is loaded.
+// Foreign keys come last, once the indexes they rely on exist.
+func dumpPostgresConstraints(foreignKeys bool) func(context.Context, *sql.Tx, *dumpWriter) error {
+	kinds := "'p', 'u', 'c', 'x'"
+	if foreignKeys {
+		kinds = "'f'"
+	}
+
+	return func(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+		rows, err := queryStrings(ctx, tx, `SELECT n.nspname, c.relname, con.conname, pg_get_constraintdef(con.oid)
+			FROM pg_constraint con
+			JOIN pg_class c ON c.oid = con.conrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE con.contype IN (`+kinds+`) AND con.conislocal
+			AND c.relkind = 'r' AND NOT c.relispartition AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_class", "c.oid")+`
+			ORDER BY 1, 2, 3`)
+		if err != nil {
+			return err
+		}
+
+		if len(rows) > 0 {
+			out.printf("\n")
+		}
+		for _, row := range rows {
+			out.statement(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s", pgQualified(row[0], row[1]), pq.QuoteIdentifier(row[2]), row[3]))
+		}
+		return nil
+	}
+}
+
+// dumpPostgresIndexes creates the indexes that do not back a constraint
+func dumpPostgresIndexes(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT pg_get_indexdef(i.indexrelid)
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'm') AND NOT c.relispartition AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_class", "c.oid")+`
+		AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = i.indexrelid AND con.contype IN ('p', 'u', 'x'))
+		ORDER BY 1`)
+	if err != nil {
+		return err
+	}
+
+	if len(rows) > 0 {
+		out.printf("\n")
+	}
+	for _, row := range rows {
+		out.statement(row[0])
+	}
+	return nil
+}
+
+func dumpPostgresTriggers(ctx context.Context, tx *sql.Tx, out *dumpWriter) error {
+	rows, err := queryStrings(ctx, tx, `SELECT pg_get_triggerdef(t.oid)
+		FROM pg_trigger t
+		JOIN pg_class c ON c.oid = t.tgrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE NOT t.tgisinternal AND NOT c.relispartition AND `+pgUserSchemas+` AND `+pgNotExtensionMember("pg_class", "c.oid")+`
+		ORDER BY 1`)
+	if err != nil {
+		return err
+	}
+
+	if len(rows) > 0 {
+		out.printf("\n")
+	}
+	for _, row := range rows {
+		out.statement(row[0])
+	}
+	return nil
+}
+
+// Load runs a SQL dump against the postgres database of an instance
+func (e *PostgresEngine) Load(ctx context.Context, instanceID, format string, r io.Reader, progress func(done, total int)) error {
+	if format != DumpFormatSQL {
+		return unsupportedDumpFormat("postgres", format)
+	}
+
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	return loadSQLDump(ctx, "postgres", postgresDSN(instance.Port, instance.Username, instance.Password, "postgres"), r, progress)
+}
@@ -0,0 +1,135 @@
+package engines
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultReadyTimeout is how long engines wait for a new process to accept queries
+	DefaultReadyTimeout = 30 * time.Second
+
+	// probeInterval is the delay between readiness attempts
+	probeInterval = 200 * time.Millisecond
+
+	// probeAttemptTimeout bounds a single readiness attempt
+	probeAttemptTimeout = 2 * time.Second
+)
+
+// probeFunc makes a single protocol-level check against a running instance
+type probeFunc func(ctx context.Context) error
+
+// readyTimeout returns timeout, or the default when it is not set
+func readyTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return DefaultReadyTimeout
+	}
+	return timeout
+}
+
+// watchProcess reaps cmd in the background and reports when it exits
+func watchProcess(cmd *exec.Cmd) <-chan error {
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	return exited
+}
+
+// waitForReady retries probe until it succeeds, the timeout elapses, ctx is
+// cancelled or the process reports on exited that it is gone. exited may be nil.
+func waitForReady(ctx context.Context, timeout time.Duration, probe probeFunc, exited <-chan error) error {
+	timeout = readyTimeout(timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		attemptCtx, attemptCancel := context.WithTimeout(ctx, probeAttemptTimeout)
+		err := probe(attemptCtx)
+		attemptCancel()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("not ready after %s: %w", timeout, err)
+			}
+			return ctx.Err()
+		case exitErr := <-exited:
+			if exitErr != nil {
+				return fmt.Errorf("process exited before becoming ready: %w", exitErr)
+			}
+			return fmt.Errorf("process exited before becoming ready")
+		case <-time.After(probeInterval):
+		}
+	}
+}
+
+// sqlProbe connects with a database/sql driver and runs a trivial query
+func sqlProbe(driver, dsn string) probeFunc {
+	return func(ctx context.Context) error {
+		db, err := sql.Open(driver, dsn)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		var one int
+		return db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+	}
+}
+
+// redisProbe connects to redis and sends a PING
+func redisProbe(port int, password string) probeFunc {
+	return func(ctx context.Context) error {
+		client := redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("127.0.0.1:%d", port),
+			Password: password,
+		})
+		defer client.Close()
+
+		return client.Ping(ctx).Err()
+	}
+}
+
+// postgresDSN returns a lib/pq connection string
+func postgresDSN(port int, username, password, database string) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(username, password),
+		Host:     fmt.Sprintf("127.0.0.1:%d", port),
+		Path:     "/" + database,
+		RawQuery: "sslmode=disable",
+	}
+	return u.String()
+}
+
+// mysqlDSN returns a go-sql-driver/mysql connection string
+func mysqlDSN(port int, username, password, database string) string {
+	cfg := mysql.NewConfig()
+	cfg.User = username
+	cfg.Passwd = password
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("127.0.0.1:%d", port)
+	cfg.DBName = database
+	return cfg.FormatDSN()
+}
+
+// mysqlQuote quotes a string literal for statements that cannot take placeholders
+func mysqlQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
@@ -0,0 +1,39 @@
+package engines
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// stopTimeout is how long a server gets to shut down before it is killed
+const stopTimeout = 10 * time.Second
+
+// stopProcess terminates the server process recorded for an instance. A recorded
+// PID that now belongs to a process with a different name has been reused after
+// the server exited, so it is left alone. When the name cannot be looked up the
+// server may still be running, so that is an error.
+func stopProcess(instance *types.Instance, processName string) error {
+	if instance.PID <= 0 || !utils.IsProcessRunning(instance.PID) {
+		return nil
+	}
+
+	name, err := utils.ProcessName(instance.PID)
+	if err != nil {
+		// The process may have exited since it was checked
+		if !utils.IsProcessRunning(instance.PID) {
+			return nil
+		}
+		return fmt.Errorf("failed to identify %s process %d: %w", processName, instance.PID, err)
+	}
+	if name != processName {
+		return nil
+	}
+
+	if err := utils.TerminateProcess(instance.PID, instance.PGID, stopTimeout); err != nil {
+		return fmt.Errorf("failed to stop %s process %d: %w", processName, instance.PID, err)
+	}
+	return nil
+}
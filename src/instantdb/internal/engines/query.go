@@ -0,0 +1,278 @@
+package engines
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// QueryResult is the outcome of a statement run in a query session
+type QueryResult struct {
+	// Columns and Rows hold what the statement returned, if anything
+	Columns []string
+	Rows    [][]sql.NullString
+
+	// Status summarizes the outcome, such as "3 rows affected"
+	Status string
+}
+
+// QuerySession is a connection to an instance that statements are run on one
+// after another, so session state such as the current database carries over
+type QuerySession interface {
+	// Split returns the statements in input, and false while the last of them
+	// is unfinished and more input is needed. When final, no more input
+	// follows and an unfinished last statement is returned as it is.
+	Split(input string, final bool) (statements []string, complete bool)
+
+	// Run runs a single statement
+	Run(ctx context.Context, statement string) (*QueryResult, error)
+
+	// Tables lists the tables, or keys, whose names match a glob pattern;
+	// all of them when the pattern is empty
+	Tables(ctx context.Context, pattern string) (*QueryResult, error)
+
+	// Describe lists the columns of a table, or the properties of a key
+	Describe(ctx context.Context, name string) (*QueryResult, error)
+
+	// Close closes the connection
+	Close() error
+}
+
+// Querier is implemented by engines that can run queries through their Go driver
+type Querier interface {
+	// OpenQuery connects a query session to a running instance
+	OpenQuery(ctx context.Context, instanceID string) (QuerySession, error)
+}
+
+// sqlQuerySession runs statements on a single connection of a SQL database
+type sqlQuerySession struct {
+	db          *sql.DB
+	conn        *sql.Conn
+	mysqlSyntax bool
+
+	// tablesQuery lists tables; its argument is a LIKE pattern
+	tablesQuery string
+
+	// describeQuery lists the columns of a table; its arguments are the
+	// schema twice, empty for the current one, and the table
+	describeQuery string
+}
+
+// openSQLQuery connects a query session through a database/sql driver
+func openSQLQuery(ctx context.Context, driver, dsn string, session *sqlQuerySession) (QuerySession, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	session.db = db
+	session.conn = conn
+	return session, nil
+}
+
+// OpenQuery connects a query session to the postgres database of an instance
+func (e *PostgresEngine) OpenQuery(ctx context.Context, instanceID string) (QuerySession, error) {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("instance not found: %w", err)
+	}
+	return openSQLQuery(ctx, "postgres", postgresDSN(instance.Port, instance.Username, instance.Password, "postgres"), &sqlQuerySession{
+		tablesQuery: `SELECT table_schema AS "schema", table_name AS "name",
+	CASE table_type WHEN 'BASE TABLE' THEN 'table' WHEN 'VIEW' THEN 'view' ELSE lower(table_type) END AS "type"
+FROM information_schema.tables
+WHERE table_schema NOT IN ('pg_catalog', 'information_schema') AND table_name LIKE $1
+ORDER BY 1, 2`,
+		describeQuery: `SELECT column_name AS "column", data_type AS "type", is_nullable AS "nullable", column_default AS "default"
+FROM information_schema.columns
+WHERE (table_schema = $1 OR ($2 = '' AND table_schema = current_schema())) AND table_name = $3
+ORDER BY ordinal_position`,
+	})
+}
+
+// OpenQuery connects a query session to an instance, with no database selected
+func (e *MySQLEngine) OpenQuery(ctx context.Context, instanceID string) (QuerySession, error) {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("instance not found: %w", err)
+	}
+	return openSQLQuery(ctx, "mysql", mysqlDSN(instance.Port, instance.Username, instance.Password, ""), &sqlQuerySession{
+		mysqlSyntax: true,
+		tablesQuery: "SELECT table_schema AS `schema`, table_name AS `name`,\n" +
+			"\tCASE table_type WHEN 'BASE TABLE' THEN 'table' WHEN 'VIEW' THEN 'view' ELSE lower(table_type) END AS `type`\n" +
+			"FROM information_schema.tables\n" +
+			"WHERE table_schema NOT IN (" + mysqlSystemSchemas + ") AND table_name LIKE ?\n" +
+			"ORDER BY 1, 2",
+		describeQuery: "SELECT column_name AS `column`, column_type AS `type`, is_nullable AS `nullable`, column_default AS `default`\n" +
+			"FROM information_schema.columns\n" +
+			"WHERE (table_schema = ? OR (? = '' AND table_schema = DATABASE())) AND table_name = ?\n" +
+			"ORDER BY ordinal_position",
+	})
+}
+
+func (s *sqlQuerySession) Split(input string, final bool) ([]string, bool) {
+	if final {
+		var statements []string
+		for _, statement := range SplitSQL(input, s.mysqlSyntax) {
+			statements = append(statements, statementSource(statement))
+		}
+		return statements, true
+	}
+
+	// A statement appended after a finished script stays on its own; when the
+	// script ends inside a statement, quote or comment it is swallowed instead
+	const probe = "\ninstant_db_probe"
+	split := SplitSQL(input+probe, s.mysqlSyntax)
+	complete := len(split) > 0 && split[len(split)-1].Text == strings.TrimSpace(probe)
+	if !complete {
+		return nil, false
+	}
+
+	statements := make([]string, 0, len(split)-1)
+	for _, statement := range split[:len(split)-1] {
+		statements = append(statements, statementSource(statement))
+	}
+	return statements, true
+}
+
+// statementSource returns a statement as Run takes it, with the data of a
+// COPY ... FROM stdin statement after it
+func statementSource(statement SQLStatement) string {
+	if statement.CopyRows == nil {
+		return statement.Text
+	}
+	var source strings.Builder
+	source.WriteString(statement.Text + ";\n")
+	for _, row := range statement.CopyRows {
+		source.WriteString(row + "\n")
+	}
+	source.WriteString(`\.`)
+	return source.String()
+}
+
+func (s *sqlQuerySession) Run(ctx context.Context, statement string) (*QueryResult, error) {
+	if !s.mysqlSyntax && copyFromStdin.MatchString(statement) {
+		if split := SplitSQL(statement, false); len(split) == 1 {
+			if err := execSQLStatement(ctx, s.conn, split[0]); err != nil {
+				return nil, err
+			}
+			return &QueryResult{Status: countRows(int64(len(split[0].CopyRows))) + " copied"}, nil
+		}
+	}
+	if !sqlReturnsRows(statement) {
+		result, err := s.conn.ExecContext(ctx, statement)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return &QueryResult{Status: "OK"}, nil
+		}
+		return &QueryResult{Status: countRows(affected) + " affected"}, nil
+	}
+	return s.query(ctx, statement)
+}
+
+func (s *sqlQuerySession) Tables(ctx context.Context, pattern string) (*QueryResult, error) {
+	return s.query(ctx, s.tablesQuery, globToLike(pattern))
+}
+
+func (s *sqlQuerySession) Describe(ctx context.Context, name string) (*QueryResult, error) {
+	schema, table, qualified := strings.Cut(name, ".")
+	if !qualified {
+		schema, table = "", name
+	}
+	result, err := s.query(ctx, s.describeQuery, schema, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rows) == 0 {
+		return nil, fmt.Errorf("table %s not found", name)
+	}
+	return result, nil
+}
+
+func (s *sqlQuerySession) Close() error {
+	s.conn.Close()
+	return s.db.Close()
+}
+
+// query runs a statement and collects the rows it returns
+func (s *sqlQuerySession) query(ctx context.Context, query string, args ...interface{}) (*QueryResult, error) {
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{Columns: columns}
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		targets := make([]interface{}, len(columns))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Statements such as DDL run through Query return no columns
+	if len(columns) == 0 {
+		result.Status = "OK"
+	} else {
+		result.Status = countRows(int64(len(result.Rows)))
+	}
+	return result, nil
+}
+
+// sqlReturnsRows reports whether a statement may return rows. Plain data
+// changes are executed instead, so the number of affected rows is known.
+func sqlReturnsRows(statement string) bool {
+	fields := strings.Fields(strings.ToLower(statement))
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "insert", "update", "delete", "replace", "merge":
+		for _, field := range fields {
+			if field == "returning" {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// countRows renders a number of rows
+func countRows(n int64) string {
+	if n == 1 {
+		return "1 row"
+	}
+	return fmt.Sprintf("%d rows", n)
+}
+
+// globToLike turns a glob pattern into a LIKE pattern, matching everything
+// when the pattern is empty
+func globToLike(pattern string) string {
+	if pattern == "" {
+		return "%"
+	}
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%", "?", "_").Replace(pattern)
+}
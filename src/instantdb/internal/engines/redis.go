@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,25 +16,51 @@ import (
 
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
-	"github.com/redis/go-redis/v9"
 )
 
+func init() {
+	Register(&Definition{
+		Name:            "redis",
+		DisplayName:     "Redis",
+		DefaultUsername: "default",
+		DefaultPassword: "",
+		DefaultVersion:  redisDefaultVersion,
+		Interactive:     true,
+		ReleaseBinaries: true,
+		URLEnv:          "REDIS_URL",
+		EnvPrefix:       "REDIS_",
+		ProcessNames:    []string{"redis-server"},
+		LogFile:         "redis.log",
+		New: func(baseDir string) Engine {
+			return NewRedisEngine(baseDir)
+		},
+	})
+}
+
+// redisDefaultVersion is the Redis release used when no version is requested
+const redisDefaultVersion = "7.2.4"
+
 type RedisEngine struct {
 	baseDir   string
 	binaryDir string
+	mirror    string
 }
 
 func NewRedisEngine(baseDir string) *RedisEngine {
-	homeDir, _ := os.UserHomeDir()
-	binaryDir := filepath.Join(homeDir, ".instant-db-redis")
+	binaryDir, _ := utils.BinaryDir("redis", ".instant-db-redis")
 	return &RedisEngine{
 		baseDir:   baseDir,
 		binaryDir: binaryDir,
+		mirror:    defaultMirror,
 	}
 }
 
-func (e *RedisEngine) downloadRedis() error {
-	version := "7.2.4"
+// SetMirror replaces the base URL Redis binaries are downloaded from
+func (e *RedisEngine) SetMirror(url string) {
+	e.mirror = url
+}
+
+func (e *RedisEngine) downloadRedis(dir, version string) error {
 	platform := "darwin-universal"
 	
 	if runtime.GOOS == "linux" {
@@ -47,9 +74,9 @@ func (e *RedisEngine) downloadRedis() error {
 		ext = ".zip"
 	}
 	
-	url := fmt.Sprintf("https://github.com/db-toolkit/instantdb/releases/download/binaries-v0.1.0/redis-%s-%s%s", version, platform, ext)
+	url := fmt.Sprintf("%s/redis-%s-%s%s", e.mirror, version, platform, ext)
 	
-	tmpFile := filepath.Join(e.binaryDir, "redis"+ext)
+	tmpFile := filepath.Join(dir, "redis"+ext)
 	
 	resp, err := http.Get(url)
 	if err != nil {
@@ -57,6 +84,10 @@ func (e *RedisEngine) downloadRedis() error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download redis %s from %s: %s", version, url, resp.Status)
+	}
+
 	out, err := os.Create(tmpFile)
 	if err != nil {
 		return err
@@ -89,7 +120,7 @@ func (e *RedisEngine) downloadRedis() error {
 			return err
 		}
 
-		redisBinary := filepath.Join(e.binaryDir, "redis-server")
+		redisBinary := filepath.Join(dir, "redis-server")
 		outFile, err := os.Create(redisBinary)
 		if err != nil {
 			return err
@@ -107,17 +138,24 @@ func (e *RedisEngine) downloadRedis() error {
 	return nil
 }
 
-func (e *RedisEngine) ensureRedis() (string, error) {
-	redisBinary := filepath.Join(e.binaryDir, "redis-server")
+func (e *RedisEngine) ensureRedis(version string) (string, error) {
+	if version == "" {
+		version = redisDefaultVersion
+	}
+	dir := versionDir(e.binaryDir, version, redisDefaultVersion)
+	redisBinary := filepath.Join(dir, "redis-server")
+
+	downloadMu.Lock()
+	defer downloadMu.Unlock()
 	
 	if _, err := os.Stat(redisBinary); err == nil {
 		return redisBinary, nil
 	}
 
-	os.MkdirAll(e.binaryDir, 0755)
+	os.MkdirAll(dir, 0755)
 
-	fmt.Println("📦 Downloading Redis binaries (first time only)...")
-	if err := e.downloadRedis(); err != nil {
+	fmt.Printf("📦 Downloading Redis %s binaries (first time only)...\n", version)
+	if err := e.downloadRedis(dir, version); err != nil {
 		return "", fmt.Errorf("failed to setup redis: %w", err)
 	}
 
@@ -130,52 +168,55 @@ func (e *RedisEngine) Start(ctx context.Context, config types.Config) (*types.In
 	if config.Name == "" {
 		config.Name = fmt.Sprintf("redis-%s", instanceID[:8])
 	}
-	if config.Port == 0 {
-		port, err := utils.GetFreePort()
-		if err != nil {
-			return nil, fmt.Errorf("failed to allocate port: %w", err)
-		}
-		config.Port = port
-	}
 	if config.DataDir == "" {
 		config.DataDir = filepath.Join(e.baseDir, instanceID)
 	}
 	if config.Username == "" {
 		config.Username = "default"
 	}
+	if config.Version == "" {
+		config.Version = redisDefaultVersion
+	}
+
+	// Claim the name and port; the reservation is dropped if the start fails
+	instance, err := reserveInstance(instanceID, "redis", &config)
+	if err != nil {
+		return nil, err
+	}
+	started := false
+	defer func() {
+		if !started {
+			utils.RemoveInstance(instanceID)
+		}
+	}()
 
 	if err := os.MkdirAll(config.DataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	redisBinary, err := e.ensureRedis()
+	redisBinary, err := e.ensureRedis(config.Version)
 	if err != nil {
 		return nil, err
 	}
 
-	configFile := filepath.Join(config.DataDir, "redis.conf")
-	configContent := fmt.Sprintf(`port %d
-dir %s
-daemonize no
-save 900 1
-save 300 10
-save 60 10000
-dbfilename dump.rdb
-`, config.Port, config.DataDir)
-
-	if config.Password != "" {
-		configContent += fmt.Sprintf("requirepass %s\n", config.Password)
+	if err := writeRedisConfig(config.DataDir, config.Port, config.Password); err != nil {
+		return nil, err
 	}
 
-	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write config: %w", err)
+	// Snapshots are loaded by the server on startup, command files once it is ready
+	if isRDBSeed(config.WithData) {
+		if err := copyRDBSeed(config.WithData, config.DataDir); err != nil {
+			os.RemoveAll(config.DataDir)
+			return nil, err
+		}
 	}
 
-	cmd := exec.Command(redisBinary, configFile)
+	cmd := exec.Command(redisBinary, filepath.Join(config.DataDir, "redis.conf"))
 	cmd.Dir = config.DataDir
+	utils.SetProcessGroup(cmd)
 	
 	logFile := filepath.Join(config.DataDir, "redis.log")
-	logFd, _ := os.Create(logFile)
+	logFd, _ := openLog(logFile)
 	cmd.Stdout = logFd
 	cmd.Stderr = logFd
 
@@ -184,54 +225,72 @@ dbfilename dump.rdb
 		return nil, fmt.Errorf("failed to start redis: %w", err)
 	}
 
-	if err := e.waitForReady(config.Port, config.Password); err != nil {
+	exited := watchProcess(cmd)
+
+	if err := waitForReady(ctx, config.ReadyTimeout, redisProbe(config.Port, config.Password), exited); err != nil {
 		cmd.Process.Kill()
+		startErr := newStartError(fmt.Errorf("redis failed to start: %w", err), logFile)
 		os.RemoveAll(config.DataDir)
-		return nil, fmt.Errorf("redis failed to start: %w", err)
+		return nil, startErr
 	}
 
-	instance := &types.Instance{
-		ID:        instanceID,
-		Name:      config.Name,
-		Engine:    "redis",
-		Port:      config.Port,
-		DataDir:   config.DataDir,
-		PID:       cmd.Process.Pid,
-		Status:    "running",
-		CreatedAt: time.Now().Unix(),
-		Persist:   config.Persist,
-		Username:  config.Username,
-		Password:  config.Password,
+	if config.WithData != "" && !isRDBSeed(config.WithData) {
+		if err := loadRedisSeed(ctx, config.Port, config.Password, config.WithData); err != nil {
+			cmd.Process.Kill()
+			os.RemoveAll(config.DataDir)
+			return nil, err
+		}
 	}
 
+	instance.PID = cmd.Process.Pid
+	instance.PGID = utils.ProcessGroup(cmd.Process.Pid)
+	instance.StartedAt = time.Now().Unix()
+	instance.Status = "running"
+
 	if err := utils.SaveInstance(instance); err != nil {
 		cmd.Process.Kill()
 		os.RemoveAll(config.DataDir)
 		return nil, fmt.Errorf("failed to save instance: %w", err)
 	}
 
+	started = true
 	return instance, nil
 }
 
-func (e *RedisEngine) waitForReady(port int, password string) error {
-	opts := &redis.Options{
-		Addr: fmt.Sprintf("127.0.0.1:%d", port),
+// Seed runs a file of redis commands, one per line, or a directory of them against an instance
+func (e *RedisEngine) Seed(ctx context.Context, instanceID, path string) error {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
 	}
+
+	return loadRedisSeed(ctx, instance.Port, instance.Password, path)
+}
+
+// writeRedisConfig writes the redis.conf of an instance
+func writeRedisConfig(dataDir string, port int, password string) error {
+	configContent := fmt.Sprintf(`port %d
+dir %s
+daemonize no
+save 900 1
+save 300 10
+save 60 10000
+dbfilename dump.rdb
+`, port, dataDir)
+
 	if password != "" {
-		opts.Password = password
+		configContent += fmt.Sprintf("requirepass %s\n", password)
 	}
 
-	client := redis.NewClient(opts)
-	defer client.Close()
-
-	for i := 0; i < 30; i++ {
-		if err := client.Ping(context.Background()).Err(); err == nil {
-			return nil
-		}
-		time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dataDir, "redis.conf"), []byte(configContent), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
 	}
+	return nil
+}
 
-	return fmt.Errorf("timeout waiting for redis")
+// Relocate points the redis.conf copied with an instance's data at its own port, directory and password
+func (e *RedisEngine) Relocate(instance *types.Instance) error {
+	return writeRedisConfig(instance.DataDir, instance.Port, instance.Password)
 }
 
 func (e *RedisEngine) Stop(ctx context.Context, instanceID string) error {
@@ -240,15 +299,17 @@ func (e *RedisEngine) Stop(ctx context.Context, instanceID string) error {
 		return fmt.Errorf("instance not found: %w", err)
 	}
 
-	utils.KillProcessOnPort(instance.Port)
+	if err := stopProcess(instance, "redis-server"); err != nil {
+		return err
+	}
 
 	if !instance.Persist {
-		os.RemoveAll(instance.DataDir)
+		if err := os.RemoveAll(instance.DataDir); err != nil {
+			return fmt.Errorf("failed to remove data directory: %w", err)
+		}
 	}
 
-	metaFile := filepath.Join(os.Getenv("HOME"), ".instant-db", instanceID+".json")
-	os.Remove(metaFile)
-	return nil
+	return utils.RemoveInstance(instanceID)
 }
 
 func (e *RedisEngine) Pause(ctx context.Context, instanceID string) error {
@@ -257,11 +318,21 @@ func (e *RedisEngine) Pause(ctx context.Context, instanceID string) error {
 		return fmt.Errorf("instance not found: %w", err)
 	}
 
-	utils.KillProcessOnPort(instance.Port)
+	if instance.Paused {
+		return fmt.Errorf("instance is already paused")
+	}
+
+	if err := stopProcess(instance, "redis-server"); err != nil {
+		return err
+	}
 
-	instance.Status = "paused"
-	instance.Paused = true
-	return utils.SaveInstance(instance)
+	return utils.UpdateInstance(instanceID, func(instance *types.Instance) error {
+		instance.PID = 0
+		instance.PGID = 0
+		instance.Status = "paused"
+		instance.Paused = true
+		return nil
+	})
 }
 
 func (e *RedisEngine) Resume(ctx context.Context, instanceID string) error {
@@ -270,7 +341,11 @@ func (e *RedisEngine) Resume(ctx context.Context, instanceID string) error {
 		return fmt.Errorf("instance not found: %w", err)
 	}
 
-	redisBinary, err := e.ensureRedis()
+	if !instance.Paused {
+		return fmt.Errorf("instance is not paused")
+	}
+
+	redisBinary, err := e.ensureRedis(instance.Version)
 	if err != nil {
 		return err
 	}
@@ -278,9 +353,10 @@ func (e *RedisEngine) Resume(ctx context.Context, instanceID string) error {
 	configFile := filepath.Join(instance.DataDir, "redis.conf")
 	cmd := exec.Command(redisBinary, configFile)
 	cmd.Dir = instance.DataDir
+	utils.SetProcessGroup(cmd)
 	
 	logFile := filepath.Join(instance.DataDir, "redis.log")
-	logFd, _ := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	logFd, _ := openLog(logFile)
 	cmd.Stdout = logFd
 	cmd.Stderr = logFd
 
@@ -288,15 +364,21 @@ func (e *RedisEngine) Resume(ctx context.Context, instanceID string) error {
 		return fmt.Errorf("failed to start redis: %w", err)
 	}
 
-	if err := e.waitForReady(instance.Port, instance.Password); err != nil {
+	exited := watchProcess(cmd)
+
+	if err := waitForReady(ctx, instance.ReadyTimeout, redisProbe(instance.Port, instance.Password), exited); err != nil {
 		cmd.Process.Kill()
-		return fmt.Errorf("redis failed to start: %w", err)
+		return newStartError(fmt.Errorf("redis failed to start: %w", err), logFile)
 	}
 
-	instance.PID = cmd.Process.Pid
-	instance.Status = "running"
-	instance.Paused = false
-	return utils.SaveInstance(instance)
+	return utils.UpdateInstance(instanceID, func(instance *types.Instance) error {
+		instance.PID = cmd.Process.Pid
+		instance.PGID = utils.ProcessGroup(cmd.Process.Pid)
+		instance.StartedAt = time.Now().Unix()
+		instance.Status = "running"
+		instance.Paused = false
+		return nil
+	})
 }
 
 func (e *RedisEngine) GetConnectionURL(instanceID string) (string, error) {
@@ -306,7 +388,7 @@ func (e *RedisEngine) GetConnectionURL(instanceID string) (string, error) {
 	}
 	
 	if instance.Password != "" {
-		return fmt.Sprintf("redis://:%s@127.0.0.1:%d", instance.Password, instance.Port), nil
+		return fmt.Sprintf("redis://%s@127.0.0.1:%d", url.UserPassword("", instance.Password), instance.Port), nil
 	}
 	return fmt.Sprintf("redis://127.0.0.1:%d", instance.Port), nil
 }
@@ -316,10 +398,7 @@ func (e *RedisEngine) Status(ctx context.Context, instanceID string) (*types.Sta
 	if err != nil {
 		return nil, fmt.Errorf("instance not found: %w", err)
 	}
-	return &types.Status{
-		Running: instance.Status == "running",
-		Message: instance.Status,
-	}, nil
+	return liveStatus(ctx, instance, redisProbe(instance.Port, instance.Password)), nil
 }
 
 func (e *RedisEngine) List() ([]*types.Instance, error) {
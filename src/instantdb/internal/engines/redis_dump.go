@@ -0,0 +1,452 @@
+package engines
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisDumpEntry is a key of a JSON key dump. Strings that are not valid
+// UTF-8 make the whole entry base64 encoded.
+type redisDumpEntry struct {
+	DB     int             `json:"db"`
+	Key    string          `json:"key"`
+	Type   string          `json:"type"`
+	TTL    int64           `json:"ttl_ms,omitempty"`
+	Base64 bool            `json:"base64,omitempty"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// redisDumpMember is a member of a sorted set
+type redisDumpMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// redisDumpMessage is an entry of a stream
+type redisDumpMessage struct {
+	ID     string            `json:"id"`
+	Fields map[string]string `json:"fields"`
+}
+
+// redisClient connects to a database of an instance
+func redisClient(instance *types.Instance, db int) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("127.0.0.1:%d", instance.Port),
+		Password: instance.Password,
+		DB:       db,
+	})
+}
+
+// DumpFormats returns the formats redis dumps support, the snapshot first
+func (e *RedisEngine) DumpFormats() []string {
+	return []string{DumpFormatRDB, DumpFormatJSON}
+}
+
+// OfflineLoad reports whether a format is loaded while the instance is stopped
+func (e *RedisEngine) OfflineLoad(format string) bool {
+	return format == DumpFormatRDB
+}
+
+// Dump writes an RDB snapshot of an instance, or its keys as JSON
+func (e *RedisEngine) Dump(ctx context.Context, instanceID, format string, w io.Writer) error {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	switch format {
+	case DumpFormatRDB:
+		return dumpRedisRDB(ctx, instance, w)
+	case DumpFormatJSON:
+		return dumpRedisJSON(ctx, instance, w)
+	}
+	return unsupportedDumpFormat("redis", format)
+}
+
+// dumpRedisRDB saves a fresh snapshot and copies it
+func dumpRedisRDB(ctx context.Context, instance *types.Instance, w io.Writer) error {
+	client := redisClient(instance, 0)
+	defer client.Close()
+
+	if err := client.Save(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	file, err := os.Open(filepath.Join(instance.DataDir, "dump.rdb"))
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("failed to write dump: %w", err)
+	}
+	return nil
+}
+
+// dumpRedisJSON writes the keys of every database as a JSON array
+func dumpRedisJSON(ctx context.Context, instance *types.Instance, w io.Writer) error {
+	client := redisClient(instance, 0)
+	defer client.Close()
+
+	databases := 16
+	if config, err := client.ConfigGet(ctx, "databases").Result(); err == nil {
+		if n, err := strconv.Atoi(config["databases"]); err == nil {
+			databases = n
+		}
+	}
+
+	out := newDumpWriter(w)
+	out.printf("[")
+	first := true
+	for db := 0; db < databases; db++ {
+		dbClient := redisClient(instance, db)
+		err := dumpRedisDatabase(ctx, dbClient, db, func(entry *redisDumpEntry) error {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if !first {
+				out.printf(",")
+			}
+			out.printf("\n  %s", data)
+			first = false
+			return nil
+		})
+		dbClient.Close()
+		if err != nil {
+			return fmt.Errorf("failed to dump database %d: %w", db, err)
+		}
+	}
+	out.printf("\n]\n")
+	return out.close()
+}
+
+// dumpRedisDatabase passes every key of a database to write
+func dumpRedisDatabase(ctx context.Context, client *redis.Client, db int, write func(*redisDumpEntry) error) error {
+	size, err := client.DBSize(ctx).Result()
+	if err != nil || size == 0 {
+		return err
+	}
+
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, "", 500).Result()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			entry, err := readRedisKey(ctx, client, db, key)
+			if err != nil {
+				return fmt.Errorf("failed to read %q: %w", key, err)
+			}
+			// Keys that expired or were deleted during the scan are skipped
+			if entry == nil {
+				continue
+			}
+			if err := write(entry); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// readRedisKey reads the type, value and expiry of a key, or nil if it is gone
+func readRedisKey(ctx context.Context, client *redis.Client, db int, key string) (*redisDumpEntry, error) {
+	kind, err := client.Type(ctx, key).Result()
+	if err != nil || kind == "none" {
+		return nil, err
+	}
+
+	var value interface{}
+	switch kind {
+	case "string":
+		value, err = client.Get(ctx, key).Result()
+	case "list":
+		value, err = client.LRange(ctx, key, 0, -1).Result()
+	case "set":
+		value, err = client.SMembers(ctx, key).Result()
+	case "hash":
+		value, err = client.HGetAll(ctx, key).Result()
+	case "zset":
+		var members []redis.Z
+		members, err = client.ZRangeWithScores(ctx, key, 0, -1).Result()
+		zset := make([]redisDumpMember, len(members))
+		for i, member := range members {
+			zset[i] = redisDumpMember{Member: fmt.Sprint(member.Member), Score: member.Score}
+		}
+		value = zset
+	case "stream":
+		var messages []redis.XMessage
+		messages, err = client.XRange(ctx, key, "-", "+").Result()
+		stream := make([]redisDumpMessage, len(messages))
+		for i, message := range messages {
+			fields := make(map[string]string, len(message.Values))
+			for field, value := range message.Values {
+				fields[field] = fmt.Sprint(value)
+			}
+			stream[i] = redisDumpMessage{ID: message.ID, Fields: fields}
+		}
+		value = stream
+	default:
+		return nil, fmt.Errorf("unsupported type %s", kind)
+	}
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := client.PTTL(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &redisDumpEntry{DB: db, Key: key, Type: kind}
+	if ttl > 0 {
+		entry.TTL = ttl.Milliseconds()
+	}
+
+	// JSON strings hold UTF-8 only, so binary keys and values are encoded
+	valid := utf8.ValidString(key)
+	mapRedisStrings(value, func(s string) string {
+		valid = valid && utf8.ValidString(s)
+		return s
+	})
+	if !valid {
+		entry.Base64 = true
+		entry.Key = base64.StdEncoding.EncodeToString([]byte(key))
+		value = mapRedisStrings(value, func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		})
+	}
+
+	entry.Value, err = json.Marshal(value)
+	return entry, err
+}
+
+// decodeRedisValue decodes the value of a dump entry into the type a dump of kind holds
+func decodeRedisValue(kind string, data json.RawMessage) (interface{}, error) {
+	switch kind {
+	case "string":
+		var value string
+		err := json.Unmarshal(data, &value)
+		return value, err
+	case "list", "set":
+		var value []string
+		err := json.Unmarshal(data, &value)
+		return value, err
+	case "hash":
+		var value map[string]string
+		err := json.Unmarshal(data, &value)
+		return value, err
+	case "zset":
+		var value []redisDumpMember
+		err := json.Unmarshal(data, &value)
+		return value, err
+	case "stream":
+		var value []redisDumpMessage
+		err := json.Unmarshal(data, &value)
+		return value, err
+	}
+	return nil, fmt.Errorf("unsupported type %s", kind)
+}
+
+// mapRedisStrings applies f to every string of a key's value
+func mapRedisStrings(value interface{}, f func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return f(v)
+	case []string:
+		mapped := make([]string, len(v))
+		for i, s := range v {
+			mapped[i] = f(s)
+		}
+		return mapped
+	case map[string]string:
+		mapped := make(map[string]string, len(v))
+		for field, s := range v {
+			mapped[f(field)] = f(s)
+		}
+		return mapped
+	case []redisDumpMember:
+		mapped := make([]redisDumpMember, len(v))
+		for i, member := range v {
+			mapped[i] = redisDumpMember{Member: f(member.Member), Score: member.Score}
+		}
+		return mapped
+	case []redisDumpMessage:
+		mapped := make([]redisDumpMessage, len(v))
+		for i, message := range v {
+			mapped[i] = redisDumpMessage{ID: message.ID, Fields: mapRedisStrings(message.Fields, f).(map[string]string)}
+		}
+		return mapped
+	}
+	return value
+}
+
+// Load restores an RDB snapshot into the data directory of a stopped
+// instance, or writes the keys of a JSON dump to a running one
+func (e *RedisEngine) Load(ctx context.Context, instanceID, format string, r io.Reader, progress func(done, total int)) error {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return fmt.Errorf("instance not found: %w", err)
+	}
+
+	switch format {
+	case DumpFormatRDB:
+		if err := writeRedisRDB(instance.DataDir, r); err != nil {
+			return err
+		}
+		progress(1, 1)
+		return nil
+	case DumpFormatJSON:
+		return loadRedisJSON(ctx, instance, r, progress)
+	}
+	return unsupportedDumpFormat("redis", format)
+}
+
+// writeRedisRDB replaces the snapshot the server loads on startup
+func writeRedisRDB(dataDir string, r io.Reader) error {
+	path := filepath.Join(dataDir, "dump.rdb")
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	_, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		os.Remove(path + ".tmp")
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// loadRedisJSON replaces the keys of a JSON dump, keeping their expiry
+func loadRedisJSON(ctx context.Context, instance *types.Instance, r io.Reader, progress func(done, total int)) error {
+	var entries []redisDumpEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return fmt.Errorf("failed to read dump: %w", err)
+	}
+
+	clients := make(map[int]*redis.Client)
+	defer func() {
+		for _, client := range clients {
+			client.Close()
+		}
+	}()
+
+	for i := range entries {
+		entry := &entries[i]
+		client, ok := clients[entry.DB]
+		if !ok {
+			client = redisClient(instance, entry.DB)
+			clients[entry.DB] = client
+		}
+
+		if err := writeRedisKey(ctx, client, entry); err != nil {
+			return fmt.Errorf("failed to load key %q of database %d: %w", entry.Key, entry.DB, err)
+		}
+		progress(i+1, len(entries))
+	}
+	return nil
+}
+
+// writeRedisKey replaces a key with the value of a dump entry
+func writeRedisKey(ctx context.Context, client *redis.Client, entry *redisDumpEntry) error {
+	value, err := decodeRedisValue(entry.Type, entry.Value)
+	if err != nil {
+		return err
+	}
+
+	key := entry.Key
+	if entry.Base64 {
+		var decodeErr error
+		decode := func(s string) string {
+			data, err := base64.StdEncoding.DecodeString(s)
+			if err != nil && decodeErr == nil {
+				decodeErr = err
+			}
+			return string(data)
+		}
+		key = decode(key)
+		value = mapRedisStrings(value, decode)
+		if decodeErr != nil {
+			return fmt.Errorf("invalid base64: %w", decodeErr)
+		}
+	}
+
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		switch v := value.(type) {
+		case string:
+			pipe.Set(ctx, key, v, 0)
+		case []string:
+			if len(v) == 0 {
+				break
+			}
+			args := make([]interface{}, len(v))
+			for i, s := range v {
+				args[i] = s
+			}
+			if entry.Type == "list" {
+				pipe.RPush(ctx, key, args...)
+			} else {
+				pipe.SAdd(ctx, key, args...)
+			}
+		case map[string]string:
+			if len(v) == 0 {
+				break
+			}
+			args := make([]interface{}, 0, 2*len(v))
+			for field, s := range v {
+				args = append(args, field, s)
+			}
+			pipe.HSet(ctx, key, args...)
+		case []redisDumpMember:
+			if len(v) == 0 {
+				break
+			}
+			members := make([]redis.Z, len(v))
+			for i, member := range v {
+				members[i] = redis.Z{Member: member.Member, Score: member.Score}
+			}
+			pipe.ZAdd(ctx, key, members...)
+		case []redisDumpMessage:
+			for _, message := range v {
+				fields := make([]interface{}, 0, 2*len(message.Fields))
+				for field, s := range message.Fields {
+					fields = append(fields, field, s)
+				}
+				pipe.XAdd(ctx, &redis.XAddArgs{Stream: key, ID: message.ID, Values: fields})
+			}
+		}
+		if entry.TTL > 0 {
+			pipe.PExpire(ctx, key, time.Duration(entry.TTL)*time.Millisecond)
+		}
+		return nil
+	})
+	return err
+}
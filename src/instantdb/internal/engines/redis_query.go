@@ -0,0 +1,256 @@
+package engines
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisQuerySession runs commands on a single connection, so SELECT carries over
+type redisQuerySession struct {
+	client *redis.Client
+	conn   *redis.Conn
+}
+
+// OpenQuery connects a query session to database 0 of an instance
+func (e *RedisEngine) OpenQuery(ctx context.Context, instanceID string) (QuerySession, error) {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("instance not found: %w", err)
+	}
+
+	client := redisClient(instance, 0)
+	conn := client.Conn()
+	if err := conn.Ping(ctx).Err(); err != nil {
+		conn.Close()
+		client.Close()
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	return &redisQuerySession{client: client, conn: conn}, nil
+}
+
+// Split returns a command per line. A line ending inside a double quoted
+// argument continues on the next one.
+func (s *redisQuerySession) Split(input string, final bool) ([]string, bool) {
+	var commands []string
+	current := ""
+	for _, line := range strings.Split(input, "\n") {
+		current += line
+		if _, err := splitCommandLine(current); err != nil && strings.Contains(err.Error(), "unterminated double quote") {
+			current += "\n"
+			continue
+		}
+		if strings.TrimSpace(current) != "" {
+			commands = append(commands, strings.TrimSpace(current))
+		}
+		current = ""
+	}
+	if final && strings.TrimSpace(current) != "" {
+		return append(commands, strings.TrimSpace(current)), true
+	}
+	return commands, current == ""
+}
+
+func (s *redisQuerySession) Run(ctx context.Context, command string) (*QueryResult, error) {
+	args, err := splitCommandLine(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return &QueryResult{Status: "OK"}, nil
+	}
+
+	reply, err := s.conn.Do(ctx, args...).Result()
+	if err == redis.Nil {
+		return &QueryResult{Status: "(nil)"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return redisReplyResult(reply), nil
+}
+
+func (s *redisQuerySession) Tables(ctx context.Context, pattern string) (*QueryResult, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := s.conn.Scan(ctx, cursor, pattern, 1000).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	sort.Strings(keys)
+
+	kinds := make([]*redis.StatusCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	_, err := s.conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			kinds[i] = pipe.Type(ctx, key)
+			ttls[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{Columns: []string{"key", "type", "ttl"}, Status: redisCountKeys(len(keys))}
+	for i, key := range keys {
+		result.Rows = append(result.Rows, []sql.NullString{
+			{String: key, Valid: true},
+			{String: kinds[i].Val(), Valid: true},
+			redisTTL(ttls[i].Val()),
+		})
+	}
+	return result, nil
+}
+
+func (s *redisQuerySession) Describe(ctx context.Context, key string) (*QueryResult, error) {
+	kind, err := s.conn.Type(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var length *redis.IntCmd
+	switch kind {
+	case "none":
+		return nil, fmt.Errorf("key %s not found", key)
+	case "string":
+		length = s.conn.StrLen(ctx, key)
+	case "list":
+		length = s.conn.LLen(ctx, key)
+	case "set":
+		length = s.conn.SCard(ctx, key)
+	case "hash":
+		length = s.conn.HLen(ctx, key)
+	case "zset":
+		length = s.conn.ZCard(ctx, key)
+	case "stream":
+		length = s.conn.XLen(ctx, key)
+	}
+	ttl, err := s.conn.PTTL(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	encoding, err := s.conn.ObjectEncoding(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{Columns: []string{"property", "value"}}
+	add := func(property string, value sql.NullString) {
+		result.Rows = append(result.Rows, []sql.NullString{{String: property, Valid: true}, value})
+	}
+	add("type", sql.NullString{String: kind, Valid: true})
+	add("ttl", redisTTL(ttl))
+	add("encoding", sql.NullString{String: encoding, Valid: true})
+	if length != nil {
+		add("length", sql.NullString{String: strconv.FormatInt(length.Val(), 10), Valid: length.Err() == nil})
+	}
+	result.Status = countRows(int64(len(result.Rows)))
+	return result, nil
+}
+
+func (s *redisQuerySession) Close() error {
+	s.conn.Close()
+	return s.client.Close()
+}
+
+// redisReplyResult turns a reply into rows: a value, the elements of an
+// array or the fields of a map
+func redisReplyResult(reply interface{}) *QueryResult {
+	switch reply := reply.(type) {
+	case []interface{}:
+		result := &QueryResult{Columns: []string{"#", "value"}, Status: countRows(int64(len(reply)))}
+		for i, element := range reply {
+			result.Rows = append(result.Rows, []sql.NullString{
+				{String: strconv.Itoa(i + 1), Valid: true},
+				redisValue(element),
+			})
+		}
+		return result
+	case map[interface{}]interface{}:
+		fields := make([]string, 0, len(reply))
+		values := make(map[string]interface{}, len(reply))
+		for field, value := range reply {
+			fields = append(fields, fmt.Sprint(field))
+			values[fmt.Sprint(field)] = value
+		}
+		sort.Strings(fields)
+
+		result := &QueryResult{Columns: []string{"field", "value"}, Status: countRows(int64(len(fields)))}
+		for _, field := range fields {
+			result.Rows = append(result.Rows, []sql.NullString{{String: field, Valid: true}, redisValue(values[field])})
+		}
+		return result
+	}
+	return &QueryResult{Columns: []string{"value"}, Rows: [][]sql.NullString{{redisValue(reply)}}, Status: "1 row"}
+}
+
+// redisValue renders a reply value; nested arrays and maps as JSON
+func redisValue(value interface{}) sql.NullString {
+	switch value := value.(type) {
+	case nil:
+		return sql.NullString{}
+	case string:
+		return sql.NullString{String: value, Valid: true}
+	case []interface{}, map[interface{}]interface{}:
+		data, err := json.Marshal(redisJSONValue(value))
+		if err != nil {
+			return sql.NullString{String: fmt.Sprint(value), Valid: true}
+		}
+		return sql.NullString{String: string(data), Valid: true}
+	}
+	return sql.NullString{String: fmt.Sprint(value), Valid: true}
+}
+
+// redisJSONValue converts nested replies to values encoding/json accepts
+func redisJSONValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		converted := make([]interface{}, len(value))
+		for i, element := range value {
+			converted[i] = redisJSONValue(element)
+		}
+		return converted
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for field, element := range value {
+			converted[fmt.Sprint(field)] = redisJSONValue(element)
+		}
+		return converted
+	}
+	return value
+}
+
+// redisTTL renders the remaining time to live of a key, NULL when it has none
+func redisTTL(ttl time.Duration) sql.NullString {
+	if ttl < 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: ttl.Round(time.Millisecond).String(), Valid: true}
+}
+
+// redisCountKeys renders a number of keys
+func redisCountKeys(n int) string {
+	if n == 1 {
+		return "1 key"
+	}
+	return fmt.Sprintf("%d keys", n)
+}
@@ -0,0 +1,146 @@
+package engines
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// Definition describes a database engine that instant-db knows how to run
+type Definition struct {
+	// Name is the canonical engine name stored in instance metadata
+	Name string
+
+	// Aliases are alternative names accepted on the command line
+	Aliases []string
+
+	// DisplayName is the human readable engine name
+	DisplayName string
+
+	// DefaultUsername and DefaultPassword are used when no credentials are given
+	DefaultUsername string
+	DefaultPassword string
+
+	// DefaultVersion is the engine release used when no version is requested
+	DefaultVersion string
+
+	// DataVersionParts is the number of leading version components two
+	// releases must share to use each other's data directories; 1, the major
+	// version, when unset
+	DataVersionParts int
+
+	// URLEnv is the environment variable conventionally holding a connection URL
+	// for the engine; see URLEnvName
+	URLEnv string
+
+	// EnvPrefix prefixes the conventional HOST/PORT/USER/PASSWORD/DATABASE
+	// variables of the engine; see EnvPrefixName
+	EnvPrefix string
+
+	// ReleaseBinaries marks engines whose binaries are downloaded from the
+	// instant-db binary releases, which the global mirror setting replaces
+	ReleaseBinaries bool
+
+	// Interactive controls whether the engine is offered in the interactive picker
+	Interactive bool
+
+	// SQL marks engines that run SQL statements, which SQL init scripts need
+	SQL bool
+
+	// ProcessNames are the executable names of the engine's server processes
+	ProcessNames []string
+
+	// LogFile is the name of the server log in an instance's data directory,
+	// empty when instant-db does not know where the engine logs
+	LogFile string
+
+	// New creates the engine, storing instance data under baseDir
+	New func(baseDir string) Engine
+}
+
+var registry = make(map[string]*Definition)
+
+// URLEnvName returns the environment variable for a connection URL of the
+// engine, <NAME>_URL when the definition does not set one
+func (d *Definition) URLEnvName() string {
+	if d.URLEnv != "" {
+		return d.URLEnv
+	}
+	return d.EnvPrefixName() + "URL"
+}
+
+// DataVersion returns the part of a version that ties a data directory to
+// the releases able to use it, such as "15" for PostgreSQL 15.3.0
+func (d *Definition) DataVersion(version string) string {
+	parts := d.DataVersionParts
+	if parts <= 0 {
+		parts = 1
+	}
+	return utils.VersionPrefix(version, parts)
+}
+
+// EnvPrefixName returns the prefix of the engine's connection variables,
+// <NAME>_ when the definition does not set one
+func (d *Definition) EnvPrefixName() string {
+	if d.EnvPrefix != "" {
+		return d.EnvPrefix
+	}
+	return strings.ToUpper(strings.ReplaceAll(d.Name, "-", "_")) + "_"
+}
+
+// Register adds an engine definition to the registry.
+// It panics if the name or one of the aliases is already taken.
+func Register(def *Definition) {
+	for _, name := range append([]string{def.Name}, def.Aliases...) {
+		key := strings.ToLower(name)
+		if _, exists := registry[key]; exists {
+			panic(fmt.Sprintf("engines: %q registered twice", name))
+		}
+		registry[key] = def
+	}
+}
+
+// Lookup returns the definition registered under a name or alias
+func Lookup(name string) (*Definition, error) {
+	def, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported engine: %s (supported: %s)", name, strings.Join(Names(), ", "))
+	}
+	return def, nil
+}
+
+// Definitions returns all registered engines ordered by name
+func Definitions() []*Definition {
+	var defs []*Definition
+	for key, def := range registry {
+		if key == def.Name {
+			defs = append(defs, def)
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
+}
+
+// Names returns the canonical names of all registered engines
+func Names() []string {
+	var names []string
+	for _, def := range Definitions() {
+		names = append(names, def.Name)
+	}
+	return names
+}
+
+// InteractiveNames returns the engines offered in the interactive picker
+func InteractiveNames() []string {
+	var names []string
+	for _, def := range Definitions() {
+		if def.Interactive {
+			names = append(names, def.Name)
+		}
+	}
+	return names
+}
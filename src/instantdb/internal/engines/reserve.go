@@ -0,0 +1,38 @@
+package engines
+
+import (
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// reserveInstance records a new instance in the starting state before any work
+// is done, so concurrent starts cannot claim the same name or port. A port is
+// allocated when config.Port is 0 and written back to config.
+func reserveInstance(instanceID, engine string, config *types.Config) (*types.Instance, error) {
+	instance := &types.Instance{
+		ID:        instanceID,
+		Name:      config.Name,
+		Engine:    engine,
+		Port:      config.Port,
+		DataDir:   config.DataDir,
+		Status:    types.StateStarting,
+		CreatedAt: time.Now().Unix(),
+		Persist:   config.Persist,
+		Username:  config.Username,
+		Password:  config.Password,
+		Version:   config.Version,
+		Project:   config.Project,
+
+		ReadyTimeout:  readyTimeout(config.ReadyTimeout),
+		RestartPolicy: config.RestartPolicy,
+	}
+
+	if err := utils.ReserveInstance(instance); err != nil {
+		return nil, err
+	}
+
+	config.Port = instance.Port
+	return instance, nil
+}
@@ -0,0 +1,543 @@
+package engines
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxStatementSnippet bounds how much of a failing statement is shown in errors
+const maxStatementSnippet = 200
+
+// SeedError reports the statement of a seed file that failed to load
+type SeedError struct {
+	File      string
+	Line      int
+	Statement string
+	Err       error
+}
+
+func (e *SeedError) Error() string {
+	statement := e.Statement
+	if len(statement) > maxStatementSnippet {
+		statement = statement[:maxStatementSnippet] + "..."
+	}
+	return fmt.Sprintf("failed to load %s at line %d: %v\n  %s", e.File, e.Line, e.Err, statement)
+}
+
+func (e *SeedError) Unwrap() error {
+	return e.Err
+}
+
+// isSQLSeed reports whether a file holds SQL statements
+func isSQLSeed(name string) bool {
+	return strings.HasSuffix(name, ".sql") || strings.HasSuffix(name, ".sql.gz")
+}
+
+// isRDBSeed reports whether a file is a redis snapshot
+func isRDBSeed(name string) bool {
+	return strings.HasSuffix(name, ".rdb") || strings.HasSuffix(name, ".rdb.gz")
+}
+
+// seedFiles returns path itself, or the files of the directory at path that
+// match include in lexical order
+func seedFiles(path string, include func(name string) bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed data: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || !include(entry.Name()) {
+			continue
+		}
+		files = append(files, filepath.Join(path, entry.Name()))
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no seed files found in %s", path)
+	}
+	return files, nil
+}
+
+// readSeedFile returns the content of a seed file, decompressing .gz files
+func readSeedFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzr, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+		}
+		defer gzr.Close()
+		reader = gzr
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// loadSQLSeed runs a SQL file, a .sql.gz file or a directory of them statement
+// by statement on a single connection, so session settings carry over
+func loadSQLSeed(ctx context.Context, driver, dsn, path string) error {
+	files, err := seedFiles(path, isSQLSeed)
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect for seeding: %w", err)
+	}
+	defer conn.Close()
+
+	for _, file := range files {
+		script, err := readSeedFile(file)
+		if err != nil {
+			return err
+		}
+
+		for _, statement := range SplitSQL(string(script), driver == "mysql") {
+			if err := execSQLStatement(ctx, conn, statement); err != nil {
+				return &SeedError{File: file, Line: statement.Line, Statement: statement.Text, Err: err}
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadRedisSeed runs a file, or a directory of files, with one redis command per
+// line. Blank lines and lines starting with # are skipped.
+func loadRedisSeed(ctx context.Context, port int, password, path string) error {
+	files, err := seedFiles(path, func(name string) bool {
+		return !isRDBSeed(name)
+	})
+	if err != nil {
+		return err
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("127.0.0.1:%d", port),
+		Password: password,
+	})
+	defer client.Close()
+
+	for _, file := range files {
+		if isRDBSeed(file) {
+			return fmt.Errorf("%s is a snapshot; RDB files can only be loaded when an instance is started", file)
+		}
+
+		data, err := readSeedFile(file)
+		if err != nil {
+			return err
+		}
+
+		for i, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			args, err := splitCommandLine(line)
+			if err == nil {
+				err = client.Do(ctx, args...).Err()
+			}
+			if err != nil && err != redis.Nil {
+				return &SeedError{File: file, Line: i + 1, Statement: line, Err: err}
+			}
+		}
+	}
+
+	return nil
+}
+
+// copyRDBSeed places a redis snapshot where the server loads it on startup
+func copyRDBSeed(path, dataDir string) error {
+	data, err := readSeedFile(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "dump.rdb"), data, 0644); err != nil {
+		return fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+	return nil
+}
+
+// SQLStatement is a statement of a SQL script and the line it starts on. The
+// data rows following a PostgreSQL COPY ... FROM stdin statement are kept in
+// CopyRows.
+type SQLStatement struct {
+	Text     string
+	Line     int
+	CopyRows []string
+}
+
+// copyFromStdin matches COPY statements whose data follows in the script
+var copyFromStdin = regexp.MustCompile(`(?is)^COPY\s.*\sFROM\s+stdin\b`)
+
+// SplitSQL splits a script into statements at semicolons outside of quotes,
+// comments and dollar-quoted bodies. mysqlSyntax enables # comments,
+// backtick identifiers, backslash escapes in strings and DELIMITER lines;
+// otherwise E'...' strings take backslash escapes and the data of COPY ...
+// FROM stdin statements is read up to its \. line.
+func SplitSQL(script string, mysqlSyntax bool) []SQLStatement {
+	var (
+		statements []SQLStatement
+		current    strings.Builder
+		line       = 1
+		startLine  = 0
+		delimiter  = ";"
+	)
+
+	write := func(s string) {
+		if startLine == 0 && strings.TrimSpace(s) != "" {
+			startLine = line
+		}
+		current.WriteString(s)
+		line += strings.Count(s, "\n")
+	}
+	flush := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			statements = append(statements, SQLStatement{Text: text, Line: startLine})
+		}
+		current.Reset()
+		startLine = 0
+	}
+
+	for i := 0; i < len(script); {
+		c := script[i]
+		rest := script[i:]
+		lineStart := i == 0 || script[i-1] == '\n'
+
+		switch {
+		case mysqlSyntax && lineStart && isDelimiterCommand(rest):
+			// DELIMITER lines are client commands that change the terminator
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			flush()
+			if fields := strings.Fields(rest[:end]); len(fields) > 1 {
+				delimiter = fields[1]
+			}
+			i += end
+		case strings.HasPrefix(rest, "--") || (mysqlSyntax && c == '#'):
+			// Line comments are dropped, the newline is kept
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			i += end
+		case strings.HasPrefix(rest, "/*"):
+			// Block comments are kept, they may hold MySQL version hints
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			write(rest[:end])
+			i += end
+		case !mysqlSyntax && (c == 'E' || c == 'e') && strings.HasPrefix(rest[1:], "'") && (i == 0 || !isIdentifierByte(script[i-1])):
+			end := 1 + quotedEnd(rest[1:], '\'', true)
+			write(rest[:end])
+			i += end
+		case c == '\'' || c == '"' || (mysqlSyntax && c == '`'):
+			end := quotedEnd(rest, c, mysqlSyntax && c != '`')
+			write(rest[:end])
+			i += end
+		case c == '$' && !mysqlSyntax:
+			if tag := dollarTag(rest); tag != "" {
+				end := strings.Index(rest[len(tag):], tag)
+				if end < 0 {
+					end = len(rest)
+				} else {
+					end += 2 * len(tag)
+				}
+				write(rest[:end])
+				i += end
+				continue
+			}
+			write(rest[:1])
+			i++
+		case strings.HasPrefix(rest, delimiter):
+			flush()
+			i += len(delimiter)
+			if !mysqlSyntax && len(statements) > 0 && copyFromStdin.MatchString(statements[len(statements)-1].Text) {
+				// The data starts on the next line and ends with a \. line
+				rows, n := copyData(script[i:])
+				statements[len(statements)-1].CopyRows = rows
+				line += strings.Count(script[i:i+n], "\n")
+				i += n
+			}
+		default:
+			write(rest[:1])
+			i++
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// isDelimiterCommand reports whether s starts with a MySQL DELIMITER line
+func isDelimiterCommand(s string) bool {
+	s = strings.TrimLeft(s, " \t")
+	return len(s) > len("DELIMITER") && strings.EqualFold(s[:len("DELIMITER")], "DELIMITER") &&
+		(s[len("DELIMITER")] == ' ' || s[len("DELIMITER")] == '\t')
+}
+
+// isIdentifierByte reports whether c can be part of an unquoted identifier
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// copyData returns the data rows of a COPY ... FROM stdin statement, which
+// start after the end of the statement's line, and the length of the text
+// they take up including the closing \. line
+func copyData(s string) ([]string, int) {
+	i := strings.IndexByte(s, '\n')
+	if i < 0 {
+		return nil, len(s)
+	}
+	i++
+
+	var rows []string
+	for i < len(s) {
+		end := strings.IndexByte(s[i:], '\n')
+		if end < 0 {
+			end = len(s) - i
+		}
+		row := strings.TrimSuffix(s[i:i+end], "\r")
+		i += end
+		if i < len(s) {
+			i++
+		}
+		if row == `\.` {
+			break
+		}
+		rows = append(rows, row)
+	}
+	return rows, i
+}
+
+// decodeCopyRow splits a row of COPY text format into its values, with \N as NULL
+func decodeCopyRow(row string) []interface{} {
+	var values []interface{}
+	for _, field := range strings.Split(row, "\t") {
+		if field == `\N` {
+			values = append(values, nil)
+			continue
+		}
+
+		var value strings.Builder
+		for i := 0; i < len(field); i++ {
+			if field[i] != '\\' || i+1 == len(field) {
+				value.WriteByte(field[i])
+				continue
+			}
+			i++
+			switch c := field[i]; c {
+			case 'b':
+				value.WriteByte('\b')
+			case 'f':
+				value.WriteByte('\f')
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case 'v':
+				value.WriteByte('\v')
+			case 'x':
+				n := 0
+				for n < 2 && i+1+n < len(field) && isHexDigit(field[i+1+n]) {
+					n++
+				}
+				if n == 0 {
+					value.WriteByte(c)
+					continue
+				}
+				b, _ := strconv.ParseUint(field[i+1:i+1+n], 16, 8)
+				value.WriteByte(byte(b))
+				i += n
+			default:
+				if c < '0' || c > '7' {
+					value.WriteByte(c)
+					continue
+				}
+				n := 1
+				for n < 3 && i+n < len(field) && field[i+n] >= '0' && field[i+n] <= '7' {
+					n++
+				}
+				b, _ := strconv.ParseUint(field[i:i+n], 8, 8)
+				value.WriteByte(byte(b))
+				i += n - 1
+			}
+		}
+		values = append(values, value.String())
+	}
+	return values
+}
+
+// isHexDigit reports whether c is a hexadecimal digit
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// execSQLStatement runs a statement of a script on conn, streaming the data
+// rows of COPY ... FROM stdin statements to the server
+func execSQLStatement(ctx context.Context, conn *sql.Conn, statement SQLStatement) error {
+	if !copyFromStdin.MatchString(statement.Text) {
+		_, err := conn.ExecContext(ctx, statement.Text)
+		return err
+	}
+	if !strings.EqualFold(statement.Text[len(statement.Text)-len("stdin"):], "stdin") {
+		return fmt.Errorf("only COPY ... FROM stdin in the default text format is supported")
+	}
+
+	stmt, err := conn.PrepareContext(ctx, statement.Text)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range statement.CopyRows {
+		if _, err := stmt.ExecContext(ctx, decodeCopyRow(row)...); err != nil {
+			return err
+		}
+	}
+	// Executing without values ends the data and reports errors in it
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
+
+// quotedEnd returns the length of the quoted token at the start of s. Doubled
+// quotes are part of the token, as are backslash escapes when allowed.
+func quotedEnd(s string, quote byte, backslashEscapes bool) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case backslashEscapes && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// dollarTag returns the PostgreSQL dollar-quote tag ($$ or $name$) at the start of s
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case c >= '0' && c <= '9' && i > 1:
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// splitCommandLine splits a redis command line into arguments, honouring
+// double quotes with backslash escapes and literal single quotes
+func splitCommandLine(line string) ([]interface{}, error) {
+	var (
+		args    []interface{}
+		current strings.Builder
+		inArg   bool
+	)
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case c == '"':
+			inArg = true
+			closed := false
+			for i++; i < len(line); i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+					switch line[i] {
+					case 'n':
+						current.WriteByte('\n')
+					case 't':
+						current.WriteByte('\t')
+					default:
+						current.WriteByte(line[i])
+					}
+					continue
+				}
+				if line[i] == '"' {
+					closed = true
+					break
+				}
+				current.WriteByte(line[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+		case c == '\'':
+			inArg = true
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+		default:
+			inArg = true
+			current.WriteByte(c)
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
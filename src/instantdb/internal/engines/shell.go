@@ -0,0 +1,133 @@
+package engines
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+)
+
+// findClient looks for a client executable in the given directories, which
+// hold the binaries instant-db downloaded, and then on PATH. bundled reports
+// whether it came from one of the directories.
+func findClient(name string, dirs ...string) (path string, bundled bool, err error) {
+	executable := name
+	if runtime.GOOS == "windows" {
+		executable += ".exe"
+	}
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, executable)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true, nil
+		}
+	}
+
+	path, err = exec.LookPath(name)
+	if err != nil {
+		return "", false, fmt.Errorf("%s not found next to the downloaded binaries or on PATH; install it to use the shell", name)
+	}
+	return path, false, nil
+}
+
+// ShellCommand returns psql connected to the postgres database of an
+// instance, with the password passed in PGPASSWORD
+func (e *PostgresEngine) ShellCommand(instance *types.Instance, query string) (*exec.Cmd, func(), error) {
+	client, _, err := findClient("psql", filepath.Join(e.runtimeDir, postgresVersion(instance.Version), "bin"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := []string{"-h", "127.0.0.1", "-p", strconv.Itoa(instance.Port), "-U", instance.Username, "-d", "postgres"}
+	if query != "" {
+		args = append(args, "-c", query)
+	}
+
+	cmd := exec.Command(client, args...)
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+instance.Password)
+	return cmd, func() {}, nil
+}
+
+// ShellCommand returns the mysql client connected to an instance. The
+// password is passed in a temporary option file the cleanup function removes.
+func (e *MySQLEngine) ShellCommand(instance *types.Instance, query string) (*exec.Cmd, func(), error) {
+	dir := e.versionDir(instance.Version)
+	client, bundled, err := findClient("mysql", filepath.Join(dir, "bin"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() {}
+	var args []string
+	if instance.Password != "" {
+		optionFile, err := os.CreateTemp("", "instant-db-mysql-*.cnf")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to write client options: %w", err)
+		}
+		cleanup = func() {
+			os.Remove(optionFile.Name())
+		}
+
+		// CreateTemp makes the file readable by its owner only
+		_, err = fmt.Fprintf(optionFile, "[client]\npassword=%s\n", mysqlOptionValue(instance.Password))
+		if closeErr := optionFile.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to write client options: %w", err)
+		}
+
+		// The option file has to be the first argument
+		args = append(args, "--defaults-extra-file="+optionFile.Name())
+	}
+
+	args = append(args, "--protocol=TCP", "-h", "127.0.0.1", "-P", strconv.Itoa(instance.Port), "-u", instance.Username)
+	if query != "" {
+		args = append(args, "-e", query)
+	}
+
+	cmd := exec.Command(client, args...)
+	cmd.Env = os.Environ()
+	if bundled {
+		cmd.Env = append(cmd.Env, getLibraryPathEnv(dir))
+	}
+	return cmd, cleanup, nil
+}
+
+// mysqlOptionValue quotes a value for an option file, which understands the
+// backslash escapes of Go string literals for the characters that need them
+func mysqlOptionValue(value string) string {
+	return strconv.Quote(value)
+}
+
+// ShellCommand returns redis-cli connected to an instance, with the password
+// passed in REDISCLI_AUTH. A query is split into the arguments of a command.
+func (e *RedisEngine) ShellCommand(instance *types.Instance, query string) (*exec.Cmd, func(), error) {
+	client, _, err := findClient("redis-cli", versionDir(e.binaryDir, instance.Version, redisDefaultVersion))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := []string{"-h", "127.0.0.1", "-p", strconv.Itoa(instance.Port)}
+	if query != "" {
+		words, err := splitCommandLine(query)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid command: %w", err)
+		}
+		for _, word := range words {
+			args = append(args, fmt.Sprint(word))
+		}
+	}
+
+	cmd := exec.Command(client, args...)
+	cmd.Env = os.Environ()
+	if instance.Password != "" {
+		cmd.Env = append(cmd.Env, "REDISCLI_AUTH="+instance.Password)
+	}
+	return cmd, func() {}, nil
+}
@@ -0,0 +1,101 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// liveStatus inspects the data directory, process, port and protocol of an
+// instance instead of trusting the saved metadata
+func liveStatus(ctx context.Context, instance *types.Instance, probe probeFunc) *types.Status {
+	// Reserved instances have no process or data until their start completes
+	if instance.Status == types.StateStarting {
+		return &types.Status{
+			State:   types.StateStarting,
+			Message: "starting",
+		}
+	}
+
+	if _, err := os.Stat(instance.DataDir); os.IsNotExist(err) {
+		return &types.Status{
+			State:   types.StateDataMissing,
+			Message: fmt.Sprintf("data directory not found: %s", instance.DataDir),
+		}
+	}
+
+	if instance.Status == types.StateCrashed {
+		return &types.Status{
+			State:   types.StateCrashed,
+			Message: "process crashed, waiting for restart",
+		}
+	}
+
+	listening := utils.IsPortListening(instance.Port)
+	processAlive := instance.PID > 0 && utils.IsProcessRunning(instance.PID)
+
+	if instance.Paused {
+		message := "paused"
+		if listening {
+			message = fmt.Sprintf("paused (port %d is in use by another process)", instance.Port)
+		}
+		return &types.Status{
+			State:   types.StatePaused,
+			Message: message,
+		}
+	}
+
+	if instance.PID > 0 && !processAlive {
+		if listening {
+			return &types.Status{
+				State:   types.StatePortTaken,
+				Message: fmt.Sprintf("process %d is gone and port %d is held by another process", instance.PID, instance.Port),
+			}
+		}
+		return &types.Status{
+			State:   types.StateCrashed,
+			Message: fmt.Sprintf("process %d is not running", instance.PID),
+		}
+	}
+
+	if !listening {
+		if processAlive {
+			return &types.Status{
+				Running: true,
+				State:   types.StateRunning,
+				Message: fmt.Sprintf("process %d is not accepting connections on port %d", instance.PID, instance.Port),
+			}
+		}
+		return &types.Status{
+			State:   types.StateCrashed,
+			Message: fmt.Sprintf("nothing is listening on port %d", instance.Port),
+		}
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, probeAttemptTimeout)
+	defer cancel()
+
+	if err := probe(probeCtx); err != nil {
+		if processAlive {
+			return &types.Status{
+				Running: true,
+				State:   types.StateRunning,
+				Message: fmt.Sprintf("not responding: %v", err),
+			}
+		}
+		return &types.Status{
+			State:   types.StatePortTaken,
+			Message: fmt.Sprintf("port %d is held by a process that does not answer as this instance", instance.Port),
+		}
+	}
+
+	return &types.Status{
+		Running: true,
+		Healthy: true,
+		State:   types.StateRunning,
+		Message: "ok",
+	}
+}
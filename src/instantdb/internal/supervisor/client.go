@@ -0,0 +1,83 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+)
+
+// pingTimeout bounds the check for a running supervisor
+const pingTimeout = 500 * time.Millisecond
+
+// Client sends requests to a running supervisor
+type Client struct {
+	socketPath string
+}
+
+// NewClient creates a client for the supervisor socket
+func NewClient() (*Client, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{socketPath: path}, nil
+}
+
+// Detect returns a client if a supervisor is running, or nil otherwise
+func Detect() *Client {
+	client, err := NewClient()
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	if _, err := client.Do(ctx, Request{Action: ActionPing}); err != nil {
+		return nil
+	}
+	return client
+}
+
+// Do sends a request and waits for the response.
+// Errors reported by the supervisor are returned as errors.
+func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", c.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to supervisor: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request to supervisor: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read supervisor response: %w", err)
+	}
+
+	if resp.Error != "" {
+		err := errors.New(resp.Error)
+		if resp.LogTail != "" {
+			return &resp, &engines.StartError{Err: err, LogTail: resp.LogTail}
+		}
+		return &resp, err
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,88 @@
+package supervisor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// Actions understood by the supervisor
+const (
+	ActionPing     = "ping"
+	ActionStart    = "start"
+	ActionStop     = "stop"
+	ActionPause    = "pause"
+	ActionResume   = "resume"
+	ActionStatus   = "status"
+	ActionShutdown = "shutdown"
+)
+
+// Restart policies that can be attached to an instance
+const (
+	RestartNo        = "no"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+// Request is a single call sent to the supervisor over its socket
+type Request struct {
+	Action     string        `json:"action"`
+	Engine     string        `json:"engine,omitempty"`
+	Config     *types.Config `json:"config,omitempty"`
+	InstanceID string        `json:"instance_id,omitempty"`
+}
+
+// Response is the supervisor's answer to a Request
+type Response struct {
+	Error    string          `json:"error,omitempty"`
+	LogTail  string          `json:"log_tail,omitempty"`
+	Instance *types.Instance `json:"instance,omitempty"`
+	Status   *types.Status   `json:"status,omitempty"`
+	PID      int             `json:"pid,omitempty"`
+	Managed  int             `json:"managed,omitempty"`
+}
+
+// ValidateRestartPolicy checks that policy is one of the supported restart policies
+func ValidateRestartPolicy(policy string) error {
+	switch policy {
+	case "", RestartNo, RestartOnFailure, RestartAlways:
+		return nil
+	default:
+		return fmt.Errorf("invalid restart policy: %s (supported: %s, %s, %s)", policy, RestartNo, RestartOnFailure, RestartAlways)
+	}
+}
+
+// runtimeDir returns the directory holding the supervisor socket and log
+func runtimeDir() (string, error) {
+	dir, err := utils.HomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// SocketPath returns the path of the supervisor unix socket
+func SocketPath() (string, error) {
+	dir, err := runtimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "supervisor.sock"), nil
+}
+
+// LogPath returns the path of the supervisor log file
+func LogPath() (string, error) {
+	dir, err := runtimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "supervisor.log"), nil
+}
@@ -0,0 +1,61 @@
+package supervisor
+
+import (
+	"context"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+)
+
+// RemoteEngine forwards lifecycle calls for one engine to the supervisor.
+// Calls that only read metadata are answered by the local engine.
+type RemoteEngine struct {
+	engines.Engine
+	name   string
+	client *Client
+}
+
+// NewRemoteEngine wraps a local engine so lifecycle calls go through the supervisor
+func NewRemoteEngine(client *Client, name string, local engines.Engine) *RemoteEngine {
+	return &RemoteEngine{
+		Engine: local,
+		name:   name,
+		client: client,
+	}
+}
+
+// Start asks the supervisor to start a new instance
+func (r *RemoteEngine) Start(ctx context.Context, config types.Config) (*types.Instance, error) {
+	resp, err := r.client.Do(ctx, Request{Action: ActionStart, Engine: r.name, Config: &config})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Instance, nil
+}
+
+// Stop asks the supervisor to stop an instance
+func (r *RemoteEngine) Stop(ctx context.Context, instanceID string) error {
+	_, err := r.client.Do(ctx, Request{Action: ActionStop, InstanceID: instanceID})
+	return err
+}
+
+// Pause asks the supervisor to pause an instance
+func (r *RemoteEngine) Pause(ctx context.Context, instanceID string) error {
+	_, err := r.client.Do(ctx, Request{Action: ActionPause, InstanceID: instanceID})
+	return err
+}
+
+// Resume asks the supervisor to resume an instance
+func (r *RemoteEngine) Resume(ctx context.Context, instanceID string) error {
+	_, err := r.client.Do(ctx, Request{Action: ActionResume, InstanceID: instanceID})
+	return err
+}
+
+// Status asks the supervisor for the status of an instance
+func (r *RemoteEngine) Status(ctx context.Context, instanceID string) (*types.Status, error) {
+	resp, err := r.client.Do(ctx, Request{Action: ActionStatus, InstanceID: instanceID})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Status, nil
+}
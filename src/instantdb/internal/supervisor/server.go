@@ -0,0 +1,400 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+const (
+	// monitorInterval is how often instances are checked for crashes
+	monitorInterval = 2 * time.Second
+
+	// maxRestartAttempts limits consecutive restarts under the on-failure policy
+	maxRestartAttempts = 5
+
+	// maxRestartBackoff caps the delay between restart attempts
+	maxRestartBackoff = time.Minute
+
+	// stableAfter is how long an instance must stay up before its restart count is reset
+	stableAfter = time.Minute
+
+	// logRotateInterval is how often the logs of running instances are checked for rotation
+	logRotateInterval = time.Minute
+)
+
+// EngineResolver returns the local engine registered under a name
+type EngineResolver func(name string) (engines.Engine, error)
+
+// restartState tracks restart attempts for one instance
+type restartState struct {
+	attempts int
+	last     time.Time
+	next     time.Time
+	gaveUp   bool
+}
+
+// Server owns database processes on behalf of CLI invocations
+type Server struct {
+	resolve EngineResolver
+	logger  *log.Logger
+
+	// engineMu serializes engine calls, which are not safe for concurrent use
+	engineMu sync.Mutex
+
+	// mu guards the bookkeeping below
+	mu       sync.Mutex
+	owned    map[string]bool
+	restarts map[string]*restartState
+}
+
+// NewServer creates a supervisor that runs engines returned by resolve
+func NewServer(resolve EngineResolver, logger *log.Logger) *Server {
+	return &Server{
+		resolve:  resolve,
+		logger:   logger,
+		owned:    make(map[string]bool),
+		restarts: make(map[string]*restartState),
+	}
+}
+
+// Serve listens on the supervisor socket until ctx is cancelled or a shutdown is requested
+func (s *Server) Serve(ctx context.Context) error {
+	path, err := SocketPath()
+	if err != nil {
+		return err
+	}
+
+	if Detect() != nil {
+		return fmt.Errorf("supervisor is already running")
+	}
+	// A socket left behind by a supervisor that did not shut down cleanly
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	defer os.Remove(path)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	go s.monitor(ctx)
+
+	s.logger.Printf("supervisor listening on %s (pid %d)", path, os.Getpid())
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				s.logger.Printf("supervisor shutting down")
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		go s.handleConn(ctx, conn, cancel)
+	}
+}
+
+// handleConn answers a single request
+func (s *Server) handleConn(ctx context.Context, conn net.Conn, shutdown context.CancelFunc) {
+	defer conn.Close()
+
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		s.logger.Printf("invalid request: %v", err)
+		return
+	}
+
+	resp := s.handle(ctx, req)
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		s.logger.Printf("failed to send response: %v", err)
+	}
+
+	if req.Action == ActionShutdown {
+		shutdown()
+	}
+}
+
+// handle dispatches a request to the engine owning the instance
+func (s *Server) handle(ctx context.Context, req Request) *Response {
+	switch req.Action {
+	case ActionPing, ActionShutdown:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return &Response{PID: os.Getpid(), Managed: len(s.owned)}
+	case ActionStart:
+		if req.Config == nil {
+			return errorResponse(fmt.Errorf("missing instance configuration"))
+		}
+		return s.start(ctx, req.Engine, *req.Config)
+	case ActionStop, ActionPause, ActionResume, ActionStatus:
+		return s.instanceAction(ctx, req.Action, req.InstanceID)
+	default:
+		return errorResponse(fmt.Errorf("unknown action: %s", req.Action))
+	}
+}
+
+// start starts a new instance owned by the supervisor
+func (s *Server) start(ctx context.Context, engineName string, config types.Config) *Response {
+	engine, err := s.resolve(engineName)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	s.engineMu.Lock()
+	instance, err := engine.Start(ctx, config)
+	s.engineMu.Unlock()
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	s.setOwned(instance.ID, true)
+	s.logger.Printf("started %s instance %s (%s) on port %d", instance.Engine, instance.Name, instance.ID, instance.Port)
+	return &Response{Instance: instance}
+}
+
+// instanceAction runs a lifecycle action against an existing instance
+func (s *Server) instanceAction(ctx context.Context, action, instanceID string) *Response {
+	instance, err := utils.LoadInstance(instanceID)
+	if err != nil {
+		return errorResponse(fmt.Errorf("instance not found: %w", err))
+	}
+
+	engine, err := s.resolve(instance.Engine)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	s.engineMu.Lock()
+	defer s.engineMu.Unlock()
+
+	switch action {
+	case ActionStop, ActionPause:
+		if action == ActionStop {
+			err = engine.Stop(ctx, instanceID)
+		} else {
+			err = engine.Pause(ctx, instanceID)
+		}
+		s.setOwned(instanceID, false)
+	case ActionResume:
+		if err = engine.Resume(ctx, instanceID); err == nil {
+			s.setOwned(instanceID, true)
+		}
+	case ActionStatus:
+		status, err := engine.Status(ctx, instanceID)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return &Response{Status: status}
+	}
+
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	s.logger.Printf("%s %s instance %s (%s)", action, instance.Engine, instance.Name, instance.ID)
+	return &Response{}
+}
+
+// monitor periodically restarts crashed instances according to their restart
+// policy and rotates the logs of long-running ones
+func (s *Server) monitor(ctx context.Context) {
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+	rotateTicker := time.NewTicker(logRotateInterval)
+	defer rotateTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkInstances(ctx)
+		case <-rotateTicker.C:
+			s.rotateLogs()
+		}
+	}
+}
+
+// rotateLogs rotates the engine logs that outgrew engines.LogMaxSize
+func (s *Server) rotateLogs() {
+	instances, err := utils.ListInstances()
+	if err != nil {
+		s.logger.Printf("failed to list instances: %v", err)
+		return
+	}
+
+	for _, instance := range instances {
+		logFile, err := engines.InstanceLogFile(instance)
+		if err != nil {
+			continue
+		}
+		if err := engines.RotateLog(logFile); err != nil {
+			s.logger.Printf("failed to rotate log of instance %s (%s): %v", instance.Name, instance.ID, err)
+		}
+	}
+}
+
+// checkInstances restarts every instance that should be running but is not
+func (s *Server) checkInstances(ctx context.Context) {
+	instances, err := utils.ListInstances()
+	if err != nil {
+		s.logger.Printf("failed to list instances: %v", err)
+		return
+	}
+
+	for _, instance := range instances {
+		if instance.RestartPolicy == "" || instance.RestartPolicy == RestartNo {
+			continue
+		}
+
+		// Instances whose port was taken over or whose data is gone cannot be restarted
+		crashed := s.liveState(ctx, instance) == types.StateCrashed
+
+		s.mu.Lock()
+		state := s.restarts[instance.ID]
+		if !crashed {
+			if state != nil && time.Since(state.last) > stableAfter {
+				delete(s.restarts, instance.ID)
+			}
+			s.mu.Unlock()
+			continue
+		}
+
+		if state == nil {
+			state = &restartState{}
+			s.restarts[instance.ID] = state
+		}
+		if state.gaveUp || time.Now().Before(state.next) {
+			s.mu.Unlock()
+			continue
+		}
+		if instance.RestartPolicy == RestartOnFailure && state.attempts >= maxRestartAttempts {
+			state.gaveUp = true
+			s.mu.Unlock()
+			s.logger.Printf("giving up on instance %s (%s) after %d restart attempts", instance.Name, instance.ID, state.attempts)
+			continue
+		}
+
+		state.attempts++
+		state.last = time.Now()
+		state.next = state.last.Add(restartBackoff(state.attempts))
+		s.mu.Unlock()
+
+		s.logger.Printf("instance %s (%s) is not running, restarting (attempt %d)", instance.Name, instance.ID, state.attempts)
+		if err := s.restart(ctx, instance); err != nil {
+			s.logger.Printf("failed to restart instance %s (%s): %v", instance.Name, instance.ID, err)
+		}
+	}
+}
+
+// restart brings a crashed instance back up through the engine's resume path
+func (s *Server) restart(ctx context.Context, instance *types.Instance) error {
+	engine, err := s.resolve(instance.Engine)
+	if err != nil {
+		return err
+	}
+
+	s.engineMu.Lock()
+	defer s.engineMu.Unlock()
+
+	// Re-check under the engine lock in case the instance was paused or stopped meanwhile
+	instance, err = utils.LoadInstance(instance.ID)
+	if err != nil {
+		return nil
+	}
+	if s.liveStateLocked(ctx, instance) != types.StateCrashed {
+		return nil
+	}
+
+	// Resume only accepts paused instances, so record the crash first
+	err = utils.UpdateInstance(instance.ID, func(instance *types.Instance) error {
+		instance.Paused = true
+		instance.Status = types.StateCrashed
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save instance: %w", err)
+	}
+
+	if err := engine.Resume(ctx, instance.ID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.owned[instance.ID] = true
+	s.mu.Unlock()
+	return nil
+}
+
+// setOwned records whether the supervisor owns an instance's process.
+// Instances that are stopped or paused on request are no longer restarted.
+func (s *Server) setOwned(instanceID string, owned bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if owned {
+		s.owned[instanceID] = true
+		return
+	}
+	delete(s.owned, instanceID)
+	delete(s.restarts, instanceID)
+}
+
+// restartBackoff returns the delay before the next restart attempt
+func restartBackoff(attempts int) time.Duration {
+	backoff := time.Second << uint(attempts-1)
+	if backoff > maxRestartBackoff || backoff <= 0 {
+		return maxRestartBackoff
+	}
+	return backoff
+}
+
+// liveState asks the instance's engine for its live state
+func (s *Server) liveState(ctx context.Context, instance *types.Instance) string {
+	s.engineMu.Lock()
+	defer s.engineMu.Unlock()
+	return s.liveStateLocked(ctx, instance)
+}
+
+// liveStateLocked is liveState for callers already holding engineMu
+func (s *Server) liveStateLocked(ctx context.Context, instance *types.Instance) string {
+	engine, err := s.resolve(instance.Engine)
+	if err != nil {
+		return ""
+	}
+
+	status, err := engine.Status(ctx, instance.ID)
+	if err != nil {
+		return ""
+	}
+	return status.State
+}
+
+// errorResponse converts an error into a response, keeping engine log output
+func errorResponse(err error) *Response {
+	resp := &Response{Error: err.Error()}
+
+	var startErr *engines.StartError
+	if errors.As(err, &startErr) {
+		resp.LogTail = startErr.LogTail
+	}
+
+	return resp
+}
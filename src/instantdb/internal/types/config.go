@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // Config holds configuration for starting a database instance
 type Config struct {
 	Name     string
@@ -10,4 +12,16 @@ type Config struct {
 	Username string
 	Password string
 	Engine   string
+
+	// Project is the directory of the manifest that declared the instance, if any
+	Project string
+
+	// Version selects the engine release, empty for the engine's default
+	Version string
+
+	// ReadyTimeout bounds how long to wait for the instance to accept queries
+	ReadyTimeout time.Duration
+
+	// RestartPolicy controls whether the supervisor restarts the instance after a crash
+	RestartPolicy string
 }
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // Instance represents a running database instance
 type Instance struct {
 	ID        string
@@ -14,4 +16,32 @@ type Instance struct {
 	Username  string
 	Password  string
 	Paused    bool
+
+	// Project is the directory of the manifest that declared the instance, if any
+	Project string
+
+	// Branch is the git branch a copy of a project instance belongs to, empty
+	// for the instance of the default branch
+	Branch string
+
+	// Version is the engine release the instance was created with
+	Version string
+
+	// InitScripts lists the init-dir scripts applied when the instance was created
+	InitScripts []string
+
+	// PGID is the process group led by the server process, 0 when it has none
+	PGID int
+
+	// SchemaVersion is the metadata layout the record was written with
+	SchemaVersion int
+
+	// ReadyTimeout bounds how long to wait for the instance to accept queries
+	ReadyTimeout time.Duration
+
+	// StartedAt is when the server was last started or resumed, 0 when unknown
+	StartedAt int64
+
+	// RestartPolicy controls whether the supervisor restarts the instance after a crash
+	RestartPolicy string
 }
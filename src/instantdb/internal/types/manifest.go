@@ -0,0 +1,33 @@
+package types
+
+// Manifest declares the instances of a project, read from instantdb.yaml
+type Manifest struct {
+	// Project namespaces instance names, defaulting to the manifest directory name
+	Project string `yaml:"project,omitempty"`
+
+	// DefaultBranch is the git branch using the instances themselves; other
+	// branches get copies of instances declared with branches: true. It is
+	// guessed from the repository when empty.
+	DefaultBranch string `yaml:"default_branch,omitempty"`
+
+	Instances map[string]ManifestInstance `yaml:"instances"`
+
+	// Dir is the absolute directory containing the manifest
+	Dir string `yaml:"-"`
+}
+
+// ManifestInstance declares a single instance of a project
+type ManifestInstance struct {
+	Engine   string `yaml:"engine"`
+	Version  string `yaml:"version,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	Persist  bool   `yaml:"persist,omitempty"`
+
+	// Seed lists files loaded in order when the instance is first created
+	Seed []string `yaml:"seed,omitempty"`
+
+	// Branches gives every git branch its own copy of the instance
+	Branches bool `yaml:"branches,omitempty"`
+}
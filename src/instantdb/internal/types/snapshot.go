@@ -0,0 +1,23 @@
+package types
+
+// Snapshot describes a saved copy of an instance's data directory
+type Snapshot struct {
+	ID   string
+	Name string
+
+	// Instance and InstanceID identify the instance the snapshot was taken from
+	Instance   string
+	InstanceID string
+
+	Engine  string
+	Version string
+
+	// Username and Password are the credentials stored in the copied data
+	Username string
+	Password string
+
+	// Size is the size of the copied data in bytes
+	Size int64
+
+	CreatedAt int64
+}
@@ -1,8 +1,19 @@
 package types
 
+// Instance states reported by Status
+const (
+	StateStarting    = "starting"
+	StateRunning     = "running"
+	StatePaused      = "paused"
+	StateCrashed     = "crashed"
+	StatePortTaken   = "port-taken"
+	StateDataMissing = "data-missing"
+)
+
 // Status represents the current status of an instance
 type Status struct {
 	Running bool
 	Healthy bool
+	State   string
 	Message string
 }
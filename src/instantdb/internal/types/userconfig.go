@@ -0,0 +1,24 @@
+package types
+
+// UserConfig holds the user's defaults from config.yaml in the instant-db home
+type UserConfig struct {
+	DefaultEngine string `yaml:"default_engine,omitempty"`
+	Persist       bool   `yaml:"persist,omitempty"`
+
+	// PortRange limits automatically assigned ports, e.g. "50000-50999"
+	PortRange string `yaml:"port_range,omitempty"`
+
+	// Mirror replaces the base URL engine binaries are downloaded from
+	Mirror string `yaml:"mirror,omitempty"`
+
+	// Engines holds per-engine defaults keyed by canonical engine name
+	Engines map[string]EngineConfig `yaml:"engines,omitempty"`
+}
+
+// EngineConfig holds the defaults of a single engine
+type EngineConfig struct {
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	Version  string `yaml:"version,omitempty"`
+	Mirror   string `yaml:"mirror,omitempty"`
+}
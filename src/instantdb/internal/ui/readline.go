@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"errors"
+	"io"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrLineCanceled is returned by ReadLine when Ctrl+C discards the line
+var ErrLineCanceled = errors.New("line canceled")
+
+type lineModel struct {
+	textInput textinput.Model
+	history   []string
+	// index is the history entry shown, len(history) for the new line
+	index   int
+	pending string
+	err     error
+	done    bool
+}
+
+func (m lineModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m lineModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.done = true
+			return m, tea.Quit
+		case tea.KeyCtrlC:
+			m.done = true
+			m.err = ErrLineCanceled
+			return m, tea.Quit
+		case tea.KeyCtrlD:
+			if m.textInput.Value() == "" {
+				m.done = true
+				m.err = io.EOF
+				return m, tea.Quit
+			}
+		case tea.KeyUp:
+			if m.index > 0 {
+				if m.index == len(m.history) {
+					m.pending = m.textInput.Value()
+				}
+				m.index--
+				m.textInput.SetValue(m.history[m.index])
+				m.textInput.CursorEnd()
+			}
+			return m, nil
+		case tea.KeyDown:
+			if m.index < len(m.history) {
+				m.index++
+				if m.index == len(m.history) {
+					m.textInput.SetValue(m.pending)
+				} else {
+					m.textInput.SetValue(m.history[m.index])
+				}
+				m.textInput.CursorEnd()
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m lineModel) View() string {
+	if m.done {
+		// The entered line stays on screen above its output
+		if m.err != nil {
+			return ""
+		}
+		return m.textInput.PromptStyle.Render(m.textInput.Prompt) + m.textInput.Value() + "\n"
+	}
+	return m.textInput.View()
+}
+
+// ReadLine reads a line from the terminal after prompt. Up and down walk
+// through history, oldest entry first. Ctrl+C returns ErrLineCanceled and
+// Ctrl+D on an empty line io.EOF.
+func ReadLine(prompt string, history []string) (string, error) {
+	ti := textinput.New()
+	ti.Prompt = prompt
+	ti.PromptStyle = InfoStyle
+	ti.Focus()
+
+	m := lineModel{
+		textInput: ti,
+		history:   history,
+		index:     len(history),
+	}
+
+	finalModel, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return "", err
+	}
+	final := finalModel.(lineModel)
+	if final.err != nil {
+		return "", final.err
+	}
+	return final.textInput.Value(), nil
+}
@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 )
 
 var (
 	cancelFunc context.CancelFunc
+
+	// interruptHandler receives signals instead of the default cleanup while set
+	handlerMu        sync.Mutex
+	interruptHandler func(os.Signal)
 )
 
 // SetupSignalHandler sets up graceful handling for Ctrl+C and other signals
@@ -21,20 +26,46 @@ func SetupSignalHandler() context.Context {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		<-sigChan
-		fmt.Println("\n")
-		fmt.Println(WarningStyle.Render("⚠️  Interrupt received, cleaning up..."))
-		
-		// Cancel any ongoing operations
-		if cancelFunc != nil {
-			cancelFunc()
+		for sig := range sigChan {
+			handlerMu.Lock()
+			handler := interruptHandler
+			handlerMu.Unlock()
+
+			// A command that cleans up on its own takes the signal instead
+			if handler != nil {
+				handler(sig)
+				continue
+			}
+
+			fmt.Println()
+			fmt.Println(WarningStyle.Render("⚠️  Interrupt received, cleaning up..."))
+
+			// Cancel any ongoing operations
+			if cancelFunc != nil {
+				cancelFunc()
+			}
+
+			// Give a moment for cleanup
+			// Then exit
+			fmt.Println(MutedStyle.Render("👋 Goodbye!"))
+			os.Exit(130) // Standard exit code for Ctrl+C
 		}
-		
-		// Give a moment for cleanup
-		// Then exit
-		fmt.Println(MutedStyle.Render("👋 Goodbye!"))
-		os.Exit(130) // Standard exit code for Ctrl+C
 	}()
 
 	return ctx
 }
+
+// HandleInterrupts passes Ctrl+C and SIGTERM to fn instead of exiting, until
+// the returned restore function is called
+func HandleInterrupts(fn func(os.Signal)) (restore func()) {
+	handlerMu.Lock()
+	previous := interruptHandler
+	interruptHandler = fn
+	handlerMu.Unlock()
+
+	return func() {
+		handlerMu.Lock()
+		interruptHandler = previous
+		handlerMu.Unlock()
+	}
+}
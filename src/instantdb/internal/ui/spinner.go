@@ -11,6 +11,7 @@ import (
 type spinnerModel struct {
 	spinner  spinner.Model
 	message  string
+	progress string
 	done     bool
 	err      error
 	task     func() error
@@ -33,6 +34,9 @@ func (m spinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
+	case progressMsg:
+		m.progress = string(msg)
+		return m, nil
 	case taskDoneMsg:
 		m.done = true
 		m.err = msg.err
@@ -48,6 +52,9 @@ func (m spinnerModel) View() string {
 		}
 		return SuccessStyle.Render("✓ " + m.message + " complete\n")
 	}
+	if m.progress != "" {
+		return fmt.Sprintf("%s %s %s\n", m.spinner.View(), InfoStyle.Render(m.message), MutedStyle.Render(m.progress))
+	}
 	return fmt.Sprintf("%s %s\n", m.spinner.View(), InfoStyle.Render(m.message))
 }
 
@@ -62,6 +69,9 @@ type taskDoneMsg struct {
 	err error
 }
 
+// progressMsg replaces the progress shown next to the spinner message
+type progressMsg string
+
 // ShowSpinner displays a spinner while running a task
 func ShowSpinner(message string, task func() error) error {
 	s := spinner.New()
@@ -87,6 +97,37 @@ func ShowSpinner(message string, task func() error) error {
 	return nil
 }
 
+// ShowProgressSpinner displays a spinner while running a task that reports its
+// progress, such as "42%", through the function it is given
+func ShowProgressSpinner(message string, task func(progress func(string)) error) error {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = InfoStyle
+
+	var p *tea.Program
+	m := spinnerModel{
+		spinner: s,
+		message: message,
+		task: func() error {
+			return task(func(progress string) {
+				p.Send(progressMsg(progress))
+			})
+		},
+	}
+
+	p = tea.NewProgram(m)
+	finalModel, err := p.Run()
+	if err != nil {
+		return err
+	}
+
+	if finalModel, ok := finalModel.(spinnerModel); ok {
+		return finalModel.err
+	}
+
+	return nil
+}
+
 // ShowSpinnerWithDelay shows spinner with artificial delay for UX
 func ShowSpinnerWithDelay(message string, task func() error, minDuration time.Duration) error {
 	return ShowSpinner(message, func() error {
@@ -1,14 +1,19 @@
 package ui
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
 )
 
-// RenderInstanceTable renders a table of instances
-func RenderInstanceTable(instances []*types.Instance) string {
+// RenderInstanceTable renders a table of instances. Live statuses, keyed by
+// instance ID, take precedence over the saved status when present.
+func RenderInstanceTable(instances []*types.Instance, statuses map[string]*types.Status) string {
 	if len(instances) == 0 {
 		return MutedStyle.Render("No running instances found.\n\n") +
 			InfoStyle.Render("💡 Start a new instance: instant-db start\n")
@@ -26,12 +31,24 @@ func RenderInstanceTable(instances []*types.Instance) string {
 		if instance.Paused {
 			status = "paused"
 		}
+		nameStyle := SuccessStyle
+		detail := ""
+		if live, ok := statuses[instance.ID]; ok && live.State != "" {
+			status = live.State
+			if live.State != types.StateRunning && live.State != types.StatePaused && live.State != types.StateStarting {
+				nameStyle = ErrorStyle
+				detail = live.Message
+			}
+		}
 		
-		b.WriteString(SuccessStyle.Render(fmt.Sprintf("  • %s\n", instance.Name)))
+		b.WriteString(nameStyle.Render(fmt.Sprintf("  • %s\n", instance.Name)))
 		b.WriteString(fmt.Sprintf("    Engine: %s\n", instance.Engine))
 		b.WriteString(fmt.Sprintf("    ID:     %s\n", instance.ID))
 		b.WriteString(fmt.Sprintf("    Port:   %d\n", instance.Port))
 		b.WriteString(fmt.Sprintf("    Status: %s\n", status))
+		if detail != "" {
+			b.WriteString(MutedStyle.Render(fmt.Sprintf("            %s", detail)) + "\n")
+		}
 		b.WriteString("\n")
 	}
 
@@ -64,6 +81,9 @@ func RenderInstanceDetails(instance *types.Instance) string {
 	b.WriteString(fmt.Sprintf("  Port:              %d\n", instance.Port))
 	b.WriteString(fmt.Sprintf("  Username:          %s\n", instance.Username))
 	b.WriteString(fmt.Sprintf("  Password:          %s\n", instance.Password))
+	if len(instance.InitScripts) > 0 {
+		b.WriteString(fmt.Sprintf("  Init Scripts:      %d applied\n", len(instance.InitScripts)))
+	}
 	
 	if instance.Engine == "redis" && instance.Password != "" {
 		b.WriteString(fmt.Sprintf("  Connection String: %s://:%s@localhost:%d%s\n\n", 
@@ -97,9 +117,132 @@ func RenderStatus(instanceID string, status *types.Status) string {
 		healthyIcon = "✅"
 	}
 
+	if status.State != "" {
+		b.WriteString(fmt.Sprintf("  State:    %s\n", status.State))
+	}
 	b.WriteString(fmt.Sprintf("  Running:  %s\n", runningIcon))
 	b.WriteString(fmt.Sprintf("  Healthy:  %s\n", healthyIcon))
 	b.WriteString(fmt.Sprintf("  Message:  %s\n\n", status.Message))
 
 	return b.String()
 }
+
+// RenderLogTail renders the last lines of an engine log after a failure
+func RenderLogTail(tail string) string {
+	var b strings.Builder
+
+	b.WriteString("\n" + WarningStyle.Render("📄 Last lines of the engine log:") + "\n\n")
+	for _, line := range strings.Split(tail, "\n") {
+		b.WriteString(MutedStyle.Render("  "+line) + "\n")
+	}
+
+	return b.String()
+}
+
+// RenderSnapshotTable renders a list of snapshots
+func RenderSnapshotTable(snapshots []*types.Snapshot) string {
+	if len(snapshots) == 0 {
+		return MutedStyle.Render("No snapshots found.\n\n") +
+			InfoStyle.Render("💡 Create one: instant-db snapshot create <name>\n")
+	}
+
+	var b strings.Builder
+
+	b.WriteString(TitleStyle.Render(fmt.Sprintf("📸 Snapshots (%d)", len(snapshots))) + "\n\n")
+
+	for _, snapshot := range snapshots {
+		b.WriteString(SuccessStyle.Render(fmt.Sprintf("  • %s\n", snapshot.Name)))
+		b.WriteString(fmt.Sprintf("    Instance: %s\n", snapshot.Instance))
+		b.WriteString(fmt.Sprintf("    ID:       %s\n", snapshot.ID))
+		if snapshot.Version != "" {
+			b.WriteString(fmt.Sprintf("    Engine:   %s %s\n", snapshot.Engine, snapshot.Version))
+		} else {
+			b.WriteString(fmt.Sprintf("    Engine:   %s\n", snapshot.Engine))
+		}
+		b.WriteString(fmt.Sprintf("    Size:     %s\n", FormatBytes(snapshot.Size)))
+		b.WriteString(fmt.Sprintf("    Created:  %s\n", time.Unix(snapshot.CreatedAt, 0).Format("2006-01-02 15:04:05")))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// resultCellWidth is the width query result cells are cut to
+const resultCellWidth = 60
+
+// RenderResultTable renders the rows of a query result under their column
+// names. NULL values are shown muted and long values are cut.
+func RenderResultTable(columns []string, rows [][]sql.NullString) string {
+	cells := make([][]string, len(rows))
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = lipgloss.Width(column)
+	}
+	for r, row := range rows {
+		cells[r] = make([]string, len(row))
+		for i, value := range row {
+			text := "NULL"
+			if value.Valid {
+				text = resultCell(value.String)
+			}
+			cells[r][i] = text
+			if i < len(widths) && lipgloss.Width(text) > widths[i] {
+				widths[i] = lipgloss.Width(text)
+			}
+		}
+	}
+
+	pad := func(text string, width int) string {
+		return text + strings.Repeat(" ", width-lipgloss.Width(text))
+	}
+
+	var b strings.Builder
+
+	var header, rule []string
+	for i, column := range columns {
+		header = append(header, LabelStyle.Render(pad(column, widths[i])))
+		rule = append(rule, strings.Repeat("─", widths[i]))
+	}
+	b.WriteString(" " + strings.Join(header, MutedStyle.Render(" │ ")) + "\n")
+	b.WriteString(MutedStyle.Render("─"+strings.Join(rule, "─┼─")+"─") + "\n")
+
+	for r, row := range rows {
+		var line []string
+		for i := range row {
+			if i >= len(widths) {
+				break
+			}
+			text := pad(cells[r][i], widths[i])
+			if !row[i].Valid {
+				text = MutedStyle.Render(text)
+			}
+			line = append(line, text)
+		}
+		b.WriteString(" " + strings.Join(line, MutedStyle.Render(" │ ")) + "\n")
+	}
+
+	return b.String()
+}
+
+// resultCell makes a value fit on one line of a result table
+func resultCell(value string) string {
+	value = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(value)
+	if runes := []rune(value); len(runes) > resultCellWidth {
+		value = string(runes[:resultCellWidth-1]) + "…"
+	}
+	return value
+}
+
+// FormatBytes renders a size in bytes with a binary unit
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
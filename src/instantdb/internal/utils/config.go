@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileName = "config.yaml"
+
+// engineConfigKeys are the settings available under engines.<engine>
+var engineConfigKeys = []string{"username", "password", "version", "mirror"}
+
+// ConfigKeys lists the keys accepted by GetConfigValue and SetConfigValue;
+// <engine> stands for an engine name
+var ConfigKeys = []string{
+	"default_engine",
+	"persist",
+	"port_range",
+	"mirror",
+	"engines.<engine>.username",
+	"engines.<engine>.password",
+	"engines.<engine>.version",
+	"engines.<engine>.mirror",
+}
+
+// ConfigValue is a single setting of the user config
+type ConfigValue struct {
+	Key   string
+	Value string
+}
+
+// ConfigPath returns the path of the user config file in the home directory
+func ConfigPath() (string, error) {
+	dir, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFileName), nil
+}
+
+// LoadUserConfig reads and validates the user config. A missing file yields an empty config.
+func LoadUserConfig() (*types.UserConfig, error) {
+	cfg, err := ReadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateUserConfig(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReadUserConfig reads the user config without validating its values, so
+// invalid settings can still be listed and repaired
+func ReadUserConfig() (*types.UserConfig, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &types.UserConfig{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ValidateUserConfig checks the values of a user config read with ReadUserConfig
+func ValidateUserConfig(cfg *types.UserConfig) error {
+	if cfg.PortRange != "" {
+		if _, _, err := ParsePortRange(cfg.PortRange); err != nil {
+			path, _ := ConfigPath()
+			return fmt.Errorf("invalid config file %s: %w (fix it with: instant-db config set port_range <low-high>)", path, err)
+		}
+	}
+	return nil
+}
+
+// SaveUserConfig atomically writes the user config
+func SaveUserConfig(cfg *types.UserConfig) error {
+	dir, err := getMetadataDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := WriteFileAtomic(filepath.Join(dir, configFileName), data); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// GetConfigValue returns the value of a key, empty when it is not set
+func GetConfigValue(cfg *types.UserConfig, key string) (string, error) {
+	if engine, field, ok := SplitEngineKey(key); ok {
+		engineCfg := cfg.Engines[engine]
+		switch field {
+		case "username":
+			return engineCfg.Username, nil
+		case "password":
+			return engineCfg.Password, nil
+		case "version":
+			return engineCfg.Version, nil
+		case "mirror":
+			return engineCfg.Mirror, nil
+		}
+		return "", unknownKeyError(key)
+	}
+
+	switch key {
+	case "default_engine":
+		return cfg.DefaultEngine, nil
+	case "persist":
+		return strconv.FormatBool(cfg.Persist), nil
+	case "port_range":
+		return cfg.PortRange, nil
+	case "mirror":
+		return cfg.Mirror, nil
+	}
+	return "", unknownKeyError(key)
+}
+
+// SetConfigValue validates and sets the value of a key. An empty value unsets it.
+func SetConfigValue(cfg *types.UserConfig, key, value string) error {
+	if engine, field, ok := SplitEngineKey(key); ok {
+		if cfg.Engines == nil {
+			cfg.Engines = make(map[string]types.EngineConfig)
+		}
+		engineCfg := cfg.Engines[engine]
+		switch field {
+		case "username":
+			engineCfg.Username = value
+		case "password":
+			engineCfg.Password = value
+		case "version":
+			engineCfg.Version = value
+		case "mirror":
+			engineCfg.Mirror = strings.TrimSuffix(value, "/")
+		default:
+			return unknownKeyError(key)
+		}
+
+		if engineCfg == (types.EngineConfig{}) {
+			delete(cfg.Engines, engine)
+		} else {
+			cfg.Engines[engine] = engineCfg
+		}
+		return nil
+	}
+
+	switch key {
+	case "default_engine":
+		cfg.DefaultEngine = value
+	case "persist":
+		if value == "" {
+			cfg.Persist = false
+			return nil
+		}
+		persist, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for persist: %q (use true or false)", value)
+		}
+		cfg.Persist = persist
+	case "port_range":
+		if value != "" {
+			if _, _, err := ParsePortRange(value); err != nil {
+				return err
+			}
+		}
+		cfg.PortRange = value
+	case "mirror":
+		cfg.Mirror = strings.TrimSuffix(value, "/")
+	default:
+		return unknownKeyError(key)
+	}
+	return nil
+}
+
+// ConfigValues returns every setting that is set, sorted by key
+func ConfigValues(cfg *types.UserConfig) []ConfigValue {
+	var values []ConfigValue
+	add := func(key, value string) {
+		if value != "" {
+			values = append(values, ConfigValue{Key: key, Value: value})
+		}
+	}
+
+	add("default_engine", cfg.DefaultEngine)
+	if cfg.Persist {
+		add("persist", "true")
+	}
+	add("port_range", cfg.PortRange)
+	add("mirror", cfg.Mirror)
+
+	for engine := range cfg.Engines {
+		for _, field := range engineConfigKeys {
+			key := fmt.Sprintf("engines.%s.%s", engine, field)
+			value, _ := GetConfigValue(cfg, key)
+			add(key, value)
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Key < values[j].Key
+	})
+	return values
+}
+
+// ParsePortRange parses a range of ports written as "low-high"
+func ParsePortRange(value string) (int, int, error) {
+	low, high, found := strings.Cut(value, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid port range %q (use low-high, e.g. 50000-50999)", value)
+	}
+
+	lowPort, err := strconv.Atoi(strings.TrimSpace(low))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q: %w", value, err)
+	}
+	highPort, err := strconv.Atoi(strings.TrimSpace(high))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q: %w", value, err)
+	}
+
+	if lowPort < 1 || highPort > 65535 || lowPort > highPort {
+		return 0, 0, fmt.Errorf("invalid port range %q: ports must be between 1 and 65535, low first", value)
+	}
+	return lowPort, highPort, nil
+}
+
+// SplitEngineKey splits an engines.<engine>.<field> key
+func SplitEngineKey(key string) (engine, field string, ok bool) {
+	parts := strings.Split(key, ".")
+	if len(parts) != 3 || parts[0] != "engines" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// unknownKeyError lists the valid keys
+func unknownKeyError(key string) error {
+	return fmt.Errorf("unknown config key %q (valid keys: %s)", key, strings.Join(ConfigKeys, ", "))
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvVar is a single environment variable
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+// DotenvLine formats a variable as a dotenv line, quoting the value when needed
+func DotenvLine(v EnvVar) string {
+	if v.Value != "" && !strings.ContainsAny(v.Value, " \t\"'#$\\`\n") {
+		return v.Key + "=" + v.Value
+	}
+	value := strings.ReplaceAll(v.Value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "$", `\$`)
+	value = strings.ReplaceAll(value, "`", "\\`")
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return v.Key + `="` + value + `"`
+}
+
+// MergeDotenv writes vars into a dotenv file. The first line setting one of
+// the keys is replaced in place and later ones are dropped, so no stale value
+// is left to win; new keys are appended and every other line is kept as is.
+// The file is replaced atomically, and a missing file is created readable only
+// by the user.
+func MergeDotenv(path string, vars []EnvVar) error {
+	mode := os.FileMode(0600)
+	var lines []string
+
+	// A symlinked file is updated where it lives
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if content := strings.TrimRight(string(data), "\n"); content != "" {
+			lines = strings.Split(content, "\n")
+		}
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	pending := make(map[string]EnvVar)
+	for _, v := range vars {
+		pending[v.Key] = v
+	}
+
+	written := make(map[string]bool)
+	merged := lines[:0]
+	for _, line := range lines {
+		key, ok := dotenvKey(line)
+		if v, exists := pending[key]; ok && exists {
+			if written[key] {
+				continue
+			}
+			prefix := ""
+			if strings.HasPrefix(strings.TrimSpace(line), "export ") {
+				prefix = "export "
+			}
+			line = prefix + DotenvLine(v)
+			written[key] = true
+		}
+		merged = append(merged, line)
+	}
+	lines = merged
+
+	for _, v := range vars {
+		if !written[v.Key] {
+			lines = append(lines, DotenvLine(v))
+			written[v.Key] = true
+		}
+	}
+
+	content := strings.Join(lines, "\n") + "\n"
+	if err := WriteFileAtomicMode(path, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// dotenvKey returns the key set by a dotenv line, if it sets one
+func dotenvKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	line = strings.TrimPrefix(line, "export ")
+
+	key, _, found := strings.Cut(line, "=")
+	if !found {
+		return "", false
+	}
+	return strings.TrimSpace(key), true
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// CopyDir copies the directory tree at src to dst, which must not exist yet.
+// Directories, regular files and symlinks keep their permissions; sockets and
+// other special files are skipped.
+func CopyDir(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+// copyFile copies a regular file, as a copy-on-write clone where the
+// filesystem supports it
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	if err := reflink(out, in); err == nil {
+		return out.Close()
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// DirSize returns the total size of the regular files below dir
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// ReplaceDir replaces the contents of dir with a copy of src. The copy is made
+// next to dir first, so a failed copy leaves dir untouched.
+func ReplaceDir(dir, src string) error {
+	staging := dir + ".restore"
+	previous := dir + ".previous"
+	os.RemoveAll(staging)
+	os.RemoveAll(previous)
+
+	if err := CopyDir(src, staging); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to copy data: %w", err)
+	}
+
+	if err := os.Rename(dir, previous); err != nil && !os.IsNotExist(err) {
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to move data directory: %w", err)
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		os.Rename(previous, dir)
+		os.RemoveAll(staging)
+		return fmt.Errorf("failed to move data directory: %w", err)
+	}
+
+	return os.RemoveAll(previous)
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitDirs returns the git directory of the repository containing dir and the
+// common directory holding its refs, which differ for linked worktrees. Both
+// are empty when dir is not inside a repository.
+func gitDirs(dir string) (gitDir, commonDir string, err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		path := filepath.Join(dir, ".git")
+		info, err := os.Stat(path)
+		if err == nil {
+			gitDir = path
+			if !info.IsDir() {
+				// Worktrees and submodules have a .git file pointing at the git directory
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return "", "", fmt.Errorf("failed to read %s: %w", path, err)
+				}
+				target := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+				if !filepath.IsAbs(target) {
+					target = filepath.Join(dir, target)
+				}
+				gitDir = target
+			}
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", nil
+		}
+		dir = parent
+	}
+
+	commonDir = gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+	return gitDir, commonDir, nil
+}
+
+// GitBranch returns the branch checked out in the repository containing dir,
+// read from .git/HEAD. It is empty when HEAD is detached or dir is not inside
+// a repository.
+func GitBranch(dir string) (string, error) {
+	gitDir, _, err := gitDirs(dir)
+	if err != nil || gitDir == "" {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read git HEAD: %w", err)
+	}
+
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: refs/heads/")
+	if !ok {
+		return "", nil
+	}
+	return ref, nil
+}
+
+// GitDefaultBranch guesses the default branch of the repository containing dir:
+// the branch origin/HEAD points at, else main or master if they exist, else main
+func GitDefaultBranch(dir string) (string, error) {
+	_, commonDir, err := gitDirs(dir)
+	if err != nil || commonDir == "" {
+		return "main", err
+	}
+
+	if data, err := os.ReadFile(filepath.Join(commonDir, "refs", "remotes", "origin", "HEAD")); err == nil {
+		if ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: refs/remotes/origin/"); ok {
+			return ref, nil
+		}
+	}
+
+	for _, branch := range []string{"main", "master"} {
+		if gitRefExists(commonDir, "refs/heads/"+branch) {
+			return branch, nil
+		}
+	}
+	return "main", nil
+}
+
+// GitBranchExists reports whether a local branch exists in the repository containing dir
+func GitBranchExists(dir, branch string) (bool, error) {
+	_, commonDir, err := gitDirs(dir)
+	if err != nil || commonDir == "" {
+		return false, err
+	}
+	return gitRefExists(commonDir, "refs/heads/"+branch), nil
+}
+
+// gitRefExists looks a ref up as a loose file and in packed-refs
+func gitRefExists(commonDir, ref string) bool {
+	if _, err := os.Stat(filepath.Join(commonDir, filepath.FromSlash(ref))); err == nil {
+		return true
+	}
+
+	file, err := os.Open(filepath.Join(commonDir, "packed-refs"))
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == ref {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// HomeEnv overrides the instant-db home directory
+	HomeEnv = "INSTANTDB_HOME"
+
+	// CacheEnv overrides where downloaded engine binaries are cached
+	CacheEnv = "INSTANTDB_CACHE_DIR"
+)
+
+var homeOverride string
+
+// SetHomeDir overrides the instant-db home directory for this process.
+// It takes precedence over INSTANTDB_HOME.
+func SetHomeDir(dir string) {
+	homeOverride = dir
+}
+
+// HomeDir returns the instant-db home directory: the directory set with
+// SetHomeDir, $INSTANTDB_HOME or ~/.instant-db, in that order
+func HomeDir() (string, error) {
+	dir, custom := customHomeDir()
+	if custom {
+		return filepath.Abs(dir)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, metadataDir), nil
+}
+
+// customHomeDir returns the home directory override, if any
+func customHomeDir() (string, bool) {
+	if homeOverride != "" {
+		return homeOverride, true
+	}
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir, true
+	}
+	return "", false
+}
+
+// DataDir returns the directory holding instance data directories
+func DataDir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "data"), nil
+}
+
+// BinaryDir returns the directory caching the binaries of an engine.
+// $INSTANTDB_CACHE_DIR wins; a custom home keeps its own cache in <home>/bin, and the
+// default home keeps using legacyName in the user's home directory so existing
+// downloads are reused.
+func BinaryDir(engine, legacyName string) (string, error) {
+	if cache := os.Getenv(CacheEnv); cache != "" {
+		return filepath.Abs(filepath.Join(cache, engine))
+	}
+
+	if dir, custom := customHomeDir(); custom {
+		return filepath.Abs(filepath.Join(dir, "bin", engine))
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, legacyName), nil
+}
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFileName is the name of a project manifest
+const ManifestFileName = "instantdb.yaml"
+
+// FindManifest returns the path of the manifest in dir or its closest parent
+func FindManifest(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, ManifestFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no %s found in this directory or its parents", ManifestFileName)
+		}
+		dir = parent
+	}
+}
+
+// LoadManifest reads and validates a manifest. Seed paths are resolved
+// relative to the manifest directory.
+func LoadManifest(path string) (*types.Manifest, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	manifest := &types.Manifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	manifest.Dir = filepath.Dir(path)
+	if manifest.Project == "" {
+		manifest.Project = defaultProjectName(manifest.Dir)
+	}
+	if strings.Contains(manifest.Project, "/") {
+		return nil, fmt.Errorf("invalid manifest %s: project %q must not contain '/'", path, manifest.Project)
+	}
+	if len(manifest.Instances) == 0 {
+		return nil, fmt.Errorf("invalid manifest %s: no instances declared", path)
+	}
+
+	for name, instance := range manifest.Instances {
+		if name == "" || strings.ContainsAny(name, "/@") {
+			return nil, fmt.Errorf("invalid manifest %s: instance name %q must be non-empty and must not contain '/' or '@'", path, name)
+		}
+		if instance.Engine == "" {
+			return nil, fmt.Errorf("invalid manifest %s: instance %s has no engine", path, name)
+		}
+		for i, seed := range instance.Seed {
+			if !filepath.IsAbs(seed) {
+				instance.Seed[i] = filepath.Join(manifest.Dir, seed)
+			}
+		}
+	}
+
+	return manifest, nil
+}
+
+// defaultProjectName names the project of a manifest that does not set one
+// after its directory. The name a directory's instances already carry is kept;
+// a name used by the instances of another directory gets a numeric suffix, so
+// two checkouts called api become api and api-2.
+func defaultProjectName(dir string) string {
+	base := filepath.Base(dir)
+
+	instances, err := ListInstances()
+	if err != nil {
+		return base
+	}
+
+	taken := make(map[string]bool)
+	for _, instance := range instances {
+		project, _, ok := strings.Cut(instance.Name, "/")
+		if instance.Project == "" || !ok {
+			continue
+		}
+		if instance.Project == dir {
+			return project
+		}
+		taken[project] = true
+	}
+
+	name := base
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
+
+// ProjectInstanceName returns the instance name of a manifest entry, namespaced by project
+func ProjectInstanceName(manifest *types.Manifest, name string) string {
+	return manifest.Project + "/" + name
+}
+
+// BranchInstanceName returns the name of the copy of a manifest entry for a git branch
+func BranchInstanceName(manifest *types.Manifest, name, branch string) string {
+	return ProjectInstanceName(manifest, name) + "@" + branch
+}
+
+// ProjectBranch returns the git branch checked out in the manifest directory
+// when it is not the default branch. It is empty on the default branch, with a
+// detached HEAD and outside of a repository.
+func ProjectBranch(manifest *types.Manifest) (string, error) {
+	branch, err := GitBranch(manifest.Dir)
+	if err != nil || branch == "" {
+		return "", err
+	}
+
+	defaultBranch := manifest.DefaultBranch
+	if defaultBranch == "" {
+		if defaultBranch, err = GitDefaultBranch(manifest.Dir); err != nil {
+			return "", err
+		}
+	}
+
+	if branch == defaultBranch {
+		return "", nil
+	}
+	return branch, nil
+}
+
+// ListProjectInstances returns the instances declared by the manifest in dir,
+// including the copies of git branches
+func ListProjectInstances(dir string) ([]*types.Instance, error) {
+	instances, err := ListInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	var project []*types.Instance
+	for _, instance := range instances {
+		if instance.Project == dir {
+			project = append(project, instance)
+		}
+	}
+	return project, nil
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+)
+
+// CurrentSchemaVersion is the metadata schema written by this version of instant-db
+const CurrentSchemaVersion = 1
+
+// ErrNewerSchema is returned for records written by a newer version of instant-db
+var ErrNewerSchema = errors.New("instance record was written by a newer version of instant-db")
+
+// migration upgrades a raw instance record to the schema version to
+type migration struct {
+	to    int
+	apply func(record map[string]interface{}) error
+}
+
+// migrations are applied in order to records older than their target version.
+// Append a step here whenever the layout of types.Instance changes.
+var migrations = []migration{
+	{to: 1, apply: migrateToV1},
+}
+
+// migrateToV1 upgrades records written before the schema was versioned, which
+// may lack a restart policy or a status
+func migrateToV1(record map[string]interface{}) error {
+	if policy, _ := record["RestartPolicy"].(string); policy == "" {
+		record["RestartPolicy"] = "no"
+	}
+
+	if status, _ := record["Status"].(string); status == "" {
+		if paused, _ := record["Paused"].(bool); paused {
+			record["Status"] = "paused"
+		} else {
+			record["Status"] = "running"
+		}
+	}
+
+	return nil
+}
+
+// schemaVersion returns the version of a raw record; unversioned records are version 0
+func schemaVersion(record map[string]interface{}) int {
+	number, ok := record["SchemaVersion"].(json.Number)
+	if !ok {
+		return 0
+	}
+	version, _ := number.Int64()
+	return int(version)
+}
+
+// decodeInstance parses a raw record and migrates it to the current schema
+func decodeInstance(data []byte) (*types.Instance, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var record map[string]interface{}
+	if err := decoder.Decode(&record); err != nil {
+		return nil, err
+	}
+
+	version := schemaVersion(record)
+	if version > CurrentSchemaVersion {
+		return nil, fmt.Errorf("%w (schema %d, supported %d)", ErrNewerSchema, version, CurrentSchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if version >= m.to {
+			continue
+		}
+		if err := m.apply(record); err != nil {
+			return nil, fmt.Errorf("failed to migrate to schema %d: %w", m.to, err)
+		}
+		version = m.to
+	}
+	record["SchemaVersion"] = version
+
+	migrated, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var instance types.Instance
+	if err := json.Unmarshal(migrated, &instance); err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+// MigrateInstances rewrites every readable record older than the current schema
+// and returns how many were upgraded. Corrupted records are left to doctor.
+func MigrateInstances() (int, error) {
+	upgraded := 0
+
+	err := withLock(func(dir string) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read metadata directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+
+			var header struct{ SchemaVersion int }
+			if err := json.Unmarshal(data, &header); err != nil || header.SchemaVersion >= CurrentSchemaVersion {
+				continue
+			}
+
+			instance, err := readInstance(path)
+			if err != nil {
+				continue
+			}
+			if err := writeInstance(dir, instance); err != nil {
+				return err
+			}
+			upgraded++
+		}
+
+		return nil
+	})
+
+	return upgraded, err
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"net"
+	"time"
 )
 
 // GetFreePort finds and returns an available port
@@ -20,3 +21,23 @@ func GetFreePort() (int, error) {
 
 	return listener.Addr().(*net.TCPAddr).Port, nil
 }
+
+// IsPortListening reports whether something accepts TCP connections on a local port
+func IsPortListening(port int) bool {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// IsPortFree reports whether a local TCP port can be bound
+func IsPortFree(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
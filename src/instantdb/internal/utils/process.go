@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 )
 
 // IsProcessRunning checks if a process with the given PID is running
@@ -23,44 +25,109 @@ func IsProcessRunning(pid int) bool {
 	return err == nil
 }
 
-// KillProcessOnPort finds and kills the process listening on a specific port
-func KillProcessOnPort(port int) error {
-	// Use lsof to find process on port
-	cmd := exec.Command("lsof", "-ti", fmt.Sprintf(":%d", port))
+// DetachProcess configures cmd to run in its own session so it outlives the caller
+func DetachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// FindProcessOnPort returns the PID of the process listening on a port, or 0 if there is none
+func FindProcessOnPort(port int) (int, error) {
+	cmd := exec.Command("lsof", "-nP", "-t", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN")
 	output, err := cmd.Output()
 	if err != nil {
-		// No process found on port
-		return nil
+		// lsof exits non-zero when nothing matches
+		if _, ok := err.(*exec.ExitError); ok {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to run lsof: %w", err)
 	}
 
-	pidStr := strings.TrimSpace(string(output))
-	if pidStr == "" {
-		return nil
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return 0, nil
 	}
+	return strconv.Atoi(fields[0])
+}
 
-	// Split by newlines in case multiple processes
-	pids := strings.Split(pidStr, "\n")
-	
-	for _, pidLine := range pids {
-		pidLine = strings.TrimSpace(pidLine)
-		if pidLine == "" {
-			continue
-		}
+// ProcessName returns the executable name of a running process
+func ProcessName(pid int) (string, error) {
+	output, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "comm=").Output()
+	if err != nil {
+		return "", fmt.Errorf("process %d not found", pid)
+	}
+	return filepath.Base(strings.TrimSpace(string(output))), nil
+}
 
-		pid, err := strconv.Atoi(pidLine)
-		if err != nil {
-			continue // Skip invalid PIDs
-		}
+// FindProcessesUsing returns the PIDs of processes whose command line mentions path or a file below it
+func FindProcessesUsing(path string) ([]int, error) {
+	output, err := exec.Command("ps", "-eo", "pid=,args=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
 
-		// Kill the process
-		process, err := os.FindProcess(pid)
-		if err != nil {
+	var pids []int
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !MentionsPath(line, path) {
 			continue
 		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
 
-		// Send SIGTERM for graceful shutdown
-		process.Signal(syscall.SIGTERM)
+// SetProcessGroup makes cmd lead a new process group so it can be stopped together with its children
+func SetProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// ProcessGroup returns the process group led by pid, or 0 if pid does not lead its own group
+func ProcessGroup(pid int) int {
+	pgid, err := syscall.Getpgid(pid)
+	if err != nil || pgid != pid {
+		return 0
+	}
+	return pgid
+}
+
+// TerminateProcess sends SIGTERM to a process, or to its whole group when pgid is set,
+// waits up to timeout for it to exit and then escalates to SIGKILL. It returns an
+// error if the process is still running afterwards.
+func TerminateProcess(pid, pgid int, timeout time.Duration) error {
+	if !IsProcessRunning(pid) {
+		return nil
+	}
+
+	if err := signalProcess(pid, pgid, syscall.SIGTERM); err != nil {
+		return err
+	}
+	if waitForExit(pid, timeout) {
+		return nil
 	}
 
+	if err := signalProcess(pid, pgid, syscall.SIGKILL); err != nil {
+		return err
+	}
+	if waitForExit(pid, killTimeout) {
+		return nil
+	}
+
+	return fmt.Errorf("process %d is still running after SIGKILL", pid)
+}
+
+// signalProcess signals pid, or its process group when pgid is set
+func signalProcess(pid, pgid int, sig syscall.Signal) error {
+	target := pid
+	if pgid > 0 {
+		target = -pgid
+	}
+
+	if err := syscall.Kill(target, sig); err != nil && err != syscall.ESRCH {
+		return fmt.Errorf("failed to send %s to process %d: %w", sig, pid, err)
+	}
 	return nil
 }
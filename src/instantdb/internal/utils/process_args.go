@@ -0,0 +1,25 @@
+package utils
+
+import "strings"
+
+// MentionsPath reports whether a command line refers to path or a file below
+// it. Only whole path components match, so /data/abc does not match /data/abcdef.
+func MentionsPath(commandLine, path string) bool {
+	path = strings.TrimRight(path, `/\`)
+	if path == "" {
+		return false
+	}
+
+	for offset := 0; ; {
+		i := strings.Index(commandLine[offset:], path)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(path)
+		if (start == 0 || strings.ContainsRune(" \t=:'\"", rune(commandLine[start-1]))) &&
+			(end == len(commandLine) || strings.ContainsRune(" \t'\"/\\", rune(commandLine[end]))) {
+			return true
+		}
+		offset = start + 1
+	}
+}
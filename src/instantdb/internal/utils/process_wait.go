@@ -0,0 +1,18 @@
+package utils
+
+import "time"
+
+// killTimeout is how long to wait for a process to disappear after a forced kill
+const killTimeout = 5 * time.Second
+
+// waitForExit polls until pid is gone or timeout elapses, reporting whether it exited
+func waitForExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for IsProcessRunning(pid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return true
+}
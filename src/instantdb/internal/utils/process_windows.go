@@ -1,37 +1,110 @@
 package utils
 
 import (
+	"encoding/csv"
 	"fmt"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
-// KillProcessOnPort finds and kills the process listening on a specific port (Windows)
-func KillProcessOnPort(port int) error {
-	// Use netstat to find process on port
-	cmd := exec.Command("netstat", "-ano")
-	output, err := cmd.Output()
+const (
+	createNewProcessGroup = 0x00000200
+	detachedProcess       = 0x00000008
+)
+
+// DetachProcess configures cmd to run without a console so it outlives the caller (Windows)
+func DetachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: createNewProcessGroup | detachedProcess}
+}
+
+// FindProcessOnPort returns the PID of the process listening on a port, or 0 if there is none (Windows)
+func FindProcessOnPort(port int) (int, error) {
+	output, err := exec.Command("netstat", "-ano").Output()
 	if err != nil {
-		return nil
+		return 0, fmt.Errorf("failed to run netstat: %w", err)
+	}
+
+	suffix := fmt.Sprintf(":%d", port)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[3] != "LISTENING" || !strings.HasSuffix(fields[1], suffix) {
+			continue
+		}
+		return strconv.Atoi(fields[4])
+	}
+	return 0, nil
+}
+
+// ProcessName returns the executable name of a running process (Windows)
+func ProcessName(pid int) (string, error) {
+	output, err := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run tasklist: %w", err)
+	}
+
+	record, err := csv.NewReader(strings.NewReader(string(output))).Read()
+	if err != nil || len(record) < 2 {
+		return "", fmt.Errorf("process %d not found", pid)
+	}
+	return strings.TrimSuffix(record[0], ".exe"), nil
+}
+
+// FindProcessesUsing returns the PIDs of processes whose command line mentions path or a file below it (Windows)
+func FindProcessesUsing(path string) ([]int, error) {
+	script := `Get-CimInstance Win32_Process | ForEach-Object { "$($_.ProcessId) $($_.CommandLine)" }`
+	output, err := exec.Command("powershell", "-NoProfile", "-Command", script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, fmt.Sprintf(":%d", port)) && strings.Contains(line, "LISTENING") {
-			fields := strings.Fields(line)
-			if len(fields) > 4 {
-				pid, err := strconv.Atoi(fields[len(fields)-1])
-				if err != nil {
-					continue
-				}
-				
-				// Kill the process
-				killCmd := exec.Command("taskkill", "/F", "/PID", strconv.Itoa(pid))
-				killCmd.Run()
-			}
+	var pids []int
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !MentionsPath(strings.ToLower(line), strings.ToLower(path)) {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil || pid == os.Getpid() {
+			continue
 		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+// SetProcessGroup makes cmd lead a new process group (Windows)
+func SetProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: createNewProcessGroup}
+}
+
+// ProcessGroup always returns 0 on Windows, where process trees are stopped with taskkill /T
+func ProcessGroup(pid int) int {
+	return 0
+}
+
+// TerminateProcess asks a process tree to exit, waits up to timeout and then
+// kills it forcefully. It returns an error if the process is still running afterwards (Windows)
+func TerminateProcess(pid, pgid int, timeout time.Duration) error {
+	if !IsProcessRunning(pid) {
+		return nil
+	}
+
+	// Console servers usually ignore the close request, so a failure here is not fatal
+	exec.Command("taskkill", "/T", "/PID", strconv.Itoa(pid)).Run()
+	if waitForExit(pid, timeout) {
+		return nil
+	}
+
+	if output, err := exec.Command("taskkill", "/F", "/T", "/PID", strconv.Itoa(pid)).CombinedOutput(); err != nil && IsProcessRunning(pid) {
+		return fmt.Errorf("failed to kill process %d: %s", pid, strings.TrimSpace(string(output)))
+	}
+	if waitForExit(pid, killTimeout) {
+		return nil
 	}
 
-	return nil
+	return fmt.Errorf("process %d is still running after taskkill /F", pid)
 }
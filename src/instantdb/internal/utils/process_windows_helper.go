@@ -3,15 +3,23 @@
 package utils
 
 import (
-	"os"
+	"golang.org/x/sys/windows"
 )
 
+// stillActive is the exit code reported for a process that has not exited
+const stillActive = 259
+
 // IsProcessRunning checks if a process with the given PID is running (Windows)
 func IsProcessRunning(pid int) bool {
-	process, err := os.FindProcess(pid)
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
 	if err != nil {
 		return false
 	}
-	// On Windows, FindProcess always succeeds, so we can't reliably check
-	return process != nil
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
 }
@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflink makes dst a copy-on-write clone of src, sharing its blocks until
+// either file changes. It fails on filesystems without reflinks (btrfs and xfs
+// have them).
+func reflink(dst, src *os.File) error {
+	return unix.IoctlFileClone(int(dst.Fd()), int(src.Fd()))
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package utils
+
+import (
+	"errors"
+	"os"
+)
+
+// reflink is only implemented on Linux; other platforms always copy
+func reflink(dst, src *os.File) error {
+	return errors.New("reflinks are not supported on this platform")
+}
@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+)
+
+const (
+	// snapshotsDirName holds one directory per snapshot in the home directory
+	snapshotsDirName = "snapshots"
+
+	// snapshotFileName is the metadata file of a snapshot
+	snapshotFileName = "snapshot.json"
+
+	// snapshotDataDirName holds the copied data directory of a snapshot
+	snapshotDataDirName = "data"
+)
+
+// SnapshotsDir returns the directory holding snapshots
+func SnapshotsDir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, snapshotsDirName), nil
+}
+
+// SnapshotDataDir returns the directory holding the data of a snapshot
+func SnapshotDataDir(snapshot *types.Snapshot) (string, error) {
+	dir, err := SnapshotsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, snapshot.ID, snapshotDataDirName), nil
+}
+
+// CreateSnapshot copies the data directory of an instance into a new snapshot.
+// The instance must not be writing to its data while the copy is made.
+func CreateSnapshot(instance *types.Instance, name string) (*types.Snapshot, error) {
+	if name == "" {
+		name = time.Now().Format("20060102-150405")
+	}
+
+	existing, err := ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range existing {
+		if snapshot.InstanceID == instance.ID && snapshot.Name == name {
+			return nil, fmt.Errorf("snapshot %q of %s already exists", name, instance.Name)
+		}
+	}
+
+	snapshot := &types.Snapshot{
+		ID:         GenerateID(),
+		Name:       name,
+		Instance:   instance.Name,
+		InstanceID: instance.ID,
+		Engine:     instance.Engine,
+		Version:    instance.Version,
+		Username:   instance.Username,
+		Password:   instance.Password,
+		CreatedAt:  time.Now().Unix(),
+	}
+
+	dir, err := SnapshotsDir()
+	if err != nil {
+		return nil, err
+	}
+	snapshotDir := filepath.Join(dir, snapshot.ID)
+	if err := os.MkdirAll(snapshotDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	dataDir := filepath.Join(snapshotDir, snapshotDataDirName)
+	if err := CopyDir(instance.DataDir, dataDir); err != nil {
+		os.RemoveAll(snapshotDir)
+		return nil, fmt.Errorf("failed to copy data: %w", err)
+	}
+
+	if snapshot.Size, err = DirSize(dataDir); err != nil {
+		os.RemoveAll(snapshotDir)
+		return nil, fmt.Errorf("failed to measure snapshot: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		os.RemoveAll(snapshotDir)
+		return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	if err := WriteFileAtomic(filepath.Join(snapshotDir, snapshotFileName), data); err != nil {
+		os.RemoveAll(snapshotDir)
+		return nil, fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// ListSnapshots returns all snapshots, oldest first. Directories without
+// readable metadata, such as snapshots still being written, are skipped.
+func ListSnapshots() ([]*types.Snapshot, error) {
+	dir, err := SnapshotsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots directory: %w", err)
+	}
+
+	var snapshots []*types.Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name(), snapshotFileName))
+		if err != nil {
+			continue
+		}
+		var snapshot types.Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil || snapshot.ID != entry.Name() {
+			continue
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt < snapshots[j].CreatedAt
+	})
+	return snapshots, nil
+}
+
+// ResolveSnapshot finds a snapshot by ID, ID prefix or name. Names are looked up
+// among the snapshots of instanceID first, then among all snapshots.
+func ResolveSnapshot(ref, instanceID string) (*types.Snapshot, error) {
+	snapshots, err := ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.ID == ref {
+			return snapshot, nil
+		}
+	}
+
+	var byInstance, byName, byPrefix []*types.Snapshot
+	for _, snapshot := range snapshots {
+		if snapshot.Name == ref {
+			byName = append(byName, snapshot)
+			if snapshot.InstanceID == instanceID {
+				byInstance = append(byInstance, snapshot)
+			}
+		}
+		if strings.HasPrefix(snapshot.ID, ref) {
+			byPrefix = append(byPrefix, snapshot)
+		}
+	}
+
+	for _, matches := range [][]*types.Snapshot{byInstance, byName, byPrefix} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		default:
+			ids := make([]string, len(matches))
+			for i, snapshot := range matches {
+				ids[i] = snapshot.ID
+			}
+			return nil, fmt.Errorf("snapshot %q is ambiguous, use one of the IDs: %s", ref, strings.Join(ids, ", "))
+		}
+	}
+
+	return nil, fmt.Errorf("snapshot not found: %s", ref)
+}
+
+// RemoveSnapshot deletes a snapshot and its data
+func RemoveSnapshot(snapshot *types.Snapshot) error {
+	dir, err := SnapshotsDir()
+	if err != nil {
+		return err
+	}
+
+	// Drop the metadata first so a partly removed snapshot is no longer listed
+	snapshotDir := filepath.Join(dir, snapshot.ID)
+	if err := os.Remove(filepath.Join(snapshotDir, snapshotFileName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove snapshot file: %w", err)
+	}
+	if err := os.RemoveAll(snapshotDir); err != nil {
+		return fmt.Errorf("failed to remove snapshot data: %w", err)
+	}
+	return nil
+}
+
+// MajorVersion returns the major component of an engine version ("15" for "15.3.0")
+func MajorVersion(version string) string {
+	return VersionPrefix(version, 1)
+}
+
+// VersionPrefix returns the first parts components of an engine version
+// ("8.4" for "8.4.3" and 2 parts)
+func VersionPrefix(version string, parts int) string {
+	components := strings.SplitN(version, ".", parts+1)
+	if len(components) > parts {
+		components = components[:parts]
+	}
+	return strings.Join(components, ".")
+}
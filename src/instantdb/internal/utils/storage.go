@@ -2,23 +2,44 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
 )
 
 const metadataDir = ".instant-db"
 
+const (
+	// lockFileName guards read-modify-write cycles across instant-db processes
+	lockFileName = ".lock"
+
+	// backupSuffix marks the last good copy of an instance record
+	backupSuffix = ".bak"
+
+	// corruptDirName holds damaged records that could not be restored
+	corruptDirName = "corrupt"
+
+	// maxPortAttempts bounds the search for a port no other instance has reserved
+	maxPortAttempts = 20
+)
+
+// metadataMu serializes metadata access between goroutines; the lock file does
+// the same between processes
+var metadataMu sync.Mutex
+
 // getMetadataDir returns the metadata directory path
 func getMetadataDir() (string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := HomeDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+		return "", err
 	}
 	
-	dir := filepath.Join(home, metadataDir)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create metadata directory: %w", err)
 	}
@@ -26,72 +47,238 @@ func getMetadataDir() (string, error) {
 	return dir, nil
 }
 
-// SaveInstance saves instance metadata to disk
-func SaveInstance(instance *types.Instance) error {
+// withLock runs fn while holding the metadata lock
+func withLock(fn func(dir string) error) error {
 	dir, err := getMetadataDir()
 	if err != nil {
 		return err
 	}
-	
-	path := filepath.Join(dir, fmt.Sprintf("%s.json", instance.ID))
-	
+
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
+	lock, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open metadata lock: %w", err)
+	}
+	defer lock.Close()
+
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("failed to lock metadata: %w", err)
+	}
+	defer unlockFile(lock)
+
+	return fn(dir)
+}
+
+// instancePath returns the path of an instance record
+func instancePath(dir, instanceID string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.json", instanceID))
+}
+
+// SaveInstance saves instance metadata to disk
+func SaveInstance(instance *types.Instance) error {
+	return withLock(func(dir string) error {
+		return writeInstance(dir, instance)
+	})
+}
+
+// UpdateInstance loads an instance, applies fn and saves the result while
+// holding the metadata lock, so concurrent updates are not lost
+func UpdateInstance(instanceID string, fn func(instance *types.Instance) error) error {
+	return withLock(func(dir string) error {
+		instance, err := readInstance(instancePath(dir, instanceID))
+		if err != nil {
+			return err
+		}
+		if err := fn(instance); err != nil {
+			return err
+		}
+		return writeInstance(dir, instance)
+	})
+}
+
+// ReserveInstance saves a new instance after checking, under the metadata lock,
+// that no other instance uses its name or port. A free port is allocated when
+// instance.Port is 0.
+func ReserveInstance(instance *types.Instance) error {
+	return withLock(func(dir string) error {
+		existing, err := listInstances(dir)
+		if err != nil {
+			return err
+		}
+
+		reserved := make(map[int]bool)
+		for _, other := range existing {
+			if other.Name == instance.Name {
+				return fmt.Errorf("instance name %q is already in use", instance.Name)
+			}
+			if instance.Port != 0 && other.Port == instance.Port {
+				return fmt.Errorf("port %d is already used by instance %s", instance.Port, other.Name)
+			}
+			reserved[other.Port] = true
+		}
+
+		if instance.Port == 0 {
+			cfg, err := LoadUserConfig()
+			if err != nil {
+				return err
+			}
+			port, err := freePortExcluding(reserved, cfg.PortRange)
+			if err != nil {
+				return err
+			}
+			instance.Port = port
+		}
+
+		return writeInstance(dir, instance)
+	})
+}
+
+// freePortExcluding finds an available port that is not reserved by another
+// instance, within portRange when one is configured
+func freePortExcluding(reserved map[int]bool, portRange string) (int, error) {
+	if portRange != "" {
+		low, high, err := ParsePortRange(portRange)
+		if err != nil {
+			return 0, err
+		}
+		for port := low; port <= high; port++ {
+			if !reserved[port] && IsPortFree(port) {
+				return port, nil
+			}
+		}
+		return 0, fmt.Errorf("failed to allocate port: no free port in range %s", portRange)
+	}
+
+	for i := 0; i < maxPortAttempts; i++ {
+		port, err := GetFreePort()
+		if err != nil {
+			return 0, fmt.Errorf("failed to allocate port: %w", err)
+		}
+		if !reserved[port] {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("failed to allocate port: no free port after %d attempts", maxPortAttempts)
+}
+
+// writeInstance atomically replaces an instance record, keeping the previous
+// good version as a backup
+func writeInstance(dir string, instance *types.Instance) error {
+	path := instancePath(dir, instance.ID)
+
+	instance.SchemaVersion = CurrentSchemaVersion
 	data, err := json.MarshalIndent(instance, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal instance: %w", err)
 	}
+
+	if previous, err := os.ReadFile(path); err == nil && json.Valid(previous) {
+		if err := WriteFileAtomic(path+backupSuffix, previous); err != nil {
+			return fmt.Errorf("failed to back up instance file: %w", err)
+		}
+	}
 	
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := WriteFileAtomic(path, data); err != nil {
 		return fmt.Errorf("failed to write instance file: %w", err)
 	}
 	
 	return nil
 }
 
-// LoadInstance loads instance metadata from disk
-func LoadInstance(instanceID string) (*types.Instance, error) {
-	dir, err := getMetadataDir()
+// WriteFileAtomic writes data to a temporary file, syncs it and renames it over
+// path, so readers see either the old or the new content but never a partial write
+func WriteFileAtomic(path string, data []byte) error {
+	return WriteFileAtomicMode(path, data, 0644)
+}
+
+// WriteFileAtomicMode is WriteFileAtomic for a file with the permissions perm
+func WriteFileAtomicMode(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
 	if err != nil {
-		return nil, err
+		return err
 	}
-	
-	path := filepath.Join(dir, fmt.Sprintf("%s.json", instanceID))
-	
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// readInstance reads and validates a single instance record
+func readInstance(path string) (*types.Instance, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read instance file: %w", err)
 	}
 	
-	var instance types.Instance
-	if err := json.Unmarshal(data, &instance); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal instance: %w", err)
+	instance, err := decodeInstance(data)
+	if errors.Is(err, ErrNewerSchema) {
+		return nil, fmt.Errorf("instance file %s: %w", filepath.Base(path), err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("instance file %s is corrupted (run instant-db doctor --fix): %w", filepath.Base(path), err)
+	}
+
+	expectedID := strings.TrimSuffix(filepath.Base(path), ".json")
+	if instance.ID != expectedID {
+		return nil, fmt.Errorf("instance file %s is corrupted (run instant-db doctor --fix): ID %q does not match file name", filepath.Base(path), instance.ID)
 	}
 	
-	return &instance, nil
+	return instance, nil
 }
 
-// RemoveInstance removes instance metadata from disk
-func RemoveInstance(instanceID string) error {
+// LoadInstance loads instance metadata from disk
+func LoadInstance(instanceID string) (*types.Instance, error) {
 	dir, err := getMetadataDir()
 	if err != nil {
-		return err
-	}
-	
-	path := filepath.Join(dir, fmt.Sprintf("%s.json", instanceID))
-	
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove instance file: %w", err)
+		return nil, err
 	}
 	
-	return nil
+	return readInstance(instancePath(dir, instanceID))
+}
+
+// RemoveInstance removes instance metadata from disk
+func RemoveInstance(instanceID string) error {
+	return withLock(func(dir string) error {
+		path := instancePath(dir, instanceID)
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove instance file: %w", err)
+		}
+		os.Remove(path + backupSuffix)
+
+		return nil
+	})
 }
 
-// ListInstances returns all saved instances
+// ListInstances returns all saved instances. Corrupted records are skipped;
+// CheckInstances reports them.
 func ListInstances() ([]*types.Instance, error) {
 	dir, err := getMetadataDir()
 	if err != nil {
 		return nil, err
 	}
-	
+
+	return listInstances(dir)
+}
+
+// listInstances reads every readable instance record in dir
+func listInstances(dir string) ([]*types.Instance, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read metadata directory: %w", err)
@@ -103,18 +290,103 @@ func ListInstances() ([]*types.Instance, error) {
 			continue
 		}
 		
-		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		instance, err := readInstance(filepath.Join(dir, entry.Name()))
 		if err != nil {
 			continue
 		}
 		
-		var instance types.Instance
-		if err := json.Unmarshal(data, &instance); err != nil {
-			continue
-		}
-		
-		instances = append(instances, &instance)
+		instances = append(instances, instance)
 	}
 	
 	return instances, nil
 }
+
+// CorruptRecord describes an instance record that cannot be read
+type CorruptRecord struct {
+	Path string
+	Err  error
+
+	// HasBackup is true when a readable backup of the record exists
+	HasBackup bool
+}
+
+// CheckInstances returns the instance records that cannot be read
+func CheckInstances() ([]CorruptRecord, error) {
+	dir, err := getMetadataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata directory: %w", err)
+	}
+
+	var records []CorruptRecord
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		_, err := readInstance(path)
+		// Records from a newer release are intact, this release just cannot read them
+		if err != nil && !errors.Is(err, ErrNewerSchema) {
+			_, backupErr := readBackup(path)
+			records = append(records, CorruptRecord{
+				Path:      path,
+				Err:       err,
+				HasBackup: backupErr == nil,
+			})
+		}
+	}
+
+	return records, nil
+}
+
+// readBackup reads and validates the backup of an instance record
+func readBackup(path string) (*types.Instance, error) {
+	data, err := os.ReadFile(path + backupSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := decodeInstance(data)
+	if err != nil {
+		return nil, err
+	}
+	if instance.ID != strings.TrimSuffix(filepath.Base(path), ".json") {
+		return nil, fmt.Errorf("backup ID %q does not match file name", instance.ID)
+	}
+	return instance, nil
+}
+
+// RepairInstance restores a corrupted record from its backup, or moves it to the
+// corrupt directory when no usable backup exists. It returns the path the
+// record was moved to, or "" when it was restored.
+func RepairInstance(record CorruptRecord) (string, error) {
+	var moved string
+	err := withLock(func(dir string) error {
+		// Another process may have rewritten the record since it was checked
+		if _, err := readInstance(record.Path); err == nil {
+			return nil
+		}
+
+		if instance, err := readBackup(record.Path); err == nil {
+			return writeInstance(dir, instance)
+		}
+
+		corruptDir := filepath.Join(dir, corruptDirName)
+		if err := os.MkdirAll(corruptDir, 0755); err != nil {
+			return fmt.Errorf("failed to create corrupt directory: %w", err)
+		}
+
+		moved = filepath.Join(corruptDir, fmt.Sprintf("%s.%d", filepath.Base(record.Path), time.Now().Unix()))
+		if err := os.Rename(record.Path, moved); err != nil {
+			return fmt.Errorf("failed to move corrupted record: %w", err)
+		}
+		os.Remove(record.Path + backupSuffix)
+		return nil
+	})
+	return moved, err
+}
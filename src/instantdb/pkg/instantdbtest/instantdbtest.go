@@ -0,0 +1,102 @@
+// Package instantdbtest starts throwaway database instances from go test.
+//
+// Each call starts a fresh instance in its own temporary data directory,
+// registers teardown with t.Cleanup and returns a connection URL:
+//
+//	func TestRepository(t *testing.T) {
+//		db, err := sql.Open("postgres", instantdbtest.Postgres(t))
+//		...
+//	}
+//
+// Each test gets its own temporary instant-db home, so tests never touch the
+// user's instances or each other's. Engine binaries are cached in
+// $INSTANTDB_CACHE_DIR, or in the user cache directory when it is not set, so
+// they are downloaded only once. Tests using the package cannot run in parallel,
+// as the home is set through the environment.
+package instantdbtest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// setupHome points instant-db at a temporary home for the duration of the
+// test, keeping a cache directory set in the environment
+func setupHome(t testing.TB) {
+	t.Setenv(utils.HomeEnv, t.TempDir())
+
+	if os.Getenv(utils.CacheEnv) == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			cache = os.TempDir()
+		}
+		t.Setenv(utils.CacheEnv, filepath.Join(cache, "instant-db"))
+	}
+}
+
+// Postgres starts a PostgreSQL instance for the duration of the test and returns its connection URL
+func Postgres(t testing.TB) string {
+	t.Helper()
+	return Start(t, "postgres")
+}
+
+// MySQL starts a MySQL instance for the duration of the test and returns its connection URL
+func MySQL(t testing.TB) string {
+	t.Helper()
+	return Start(t, "mysql")
+}
+
+// Redis starts a Redis instance for the duration of the test and returns its connection URL
+func Redis(t testing.TB) string {
+	t.Helper()
+	return Start(t, "redis")
+}
+
+// Start starts an instance of the named engine for the duration of the test and
+// returns its connection URL. The test fails, with the tail of the engine log
+// when available, if the instance cannot be started.
+func Start(t testing.TB, engine string) string {
+	t.Helper()
+
+	def, err := engines.Lookup(engine)
+	if err != nil {
+		t.Fatalf("instantdbtest: %v", err)
+	}
+
+	setupHome(t)
+	e := def.New(t.TempDir())
+
+	ctx := context.Background()
+	instance, err := e.Start(ctx, types.Config{
+		Username: def.DefaultUsername,
+		Password: def.DefaultPassword,
+		Engine:   def.Name,
+	})
+	if err != nil {
+		var startErr *engines.StartError
+		if errors.As(err, &startErr) && startErr.LogTail != "" {
+			t.Fatalf("instantdbtest: failed to start %s: %v\n\n%s log (last lines):\n%s", def.DisplayName, err, def.DisplayName, startErr.LogTail)
+		}
+		t.Fatalf("instantdbtest: failed to start %s: %v", def.DisplayName, err)
+	}
+
+	t.Cleanup(func() {
+		if err := e.Stop(ctx, instance.ID); err != nil {
+			t.Errorf("instantdbtest: failed to stop %s instance %s: %v", def.DisplayName, instance.Name, err)
+		}
+	})
+
+	url, err := e.GetConnectionURL(instance.ID)
+	if err != nil {
+		t.Fatalf("instantdbtest: failed to get connection URL: %v", err)
+	}
+
+	return url
+}
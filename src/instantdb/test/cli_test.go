@@ -0,0 +1,272 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// cloneFailPlugin is fakePlugin, except that resuming an instance named copy fails
+const cloneFailPlugin = `#!/bin/sh
+case "$1" in
+info)   echo '{"info":{"display_name":"Fake DB"}}' ;;
+start)  echo '{"pid":0}' ;;
+status) echo '{"status":{"running":true,"healthy":true,"message":"ok"}}' ;;
+url)    echo '{"url":"fake://127.0.0.1"}' ;;
+resume) if grep -q '"name":"copy"'; then echo '{"error":"boom"}'; exit 1; fi; echo '{}' ;;
+*)      echo '{}' ;;
+esac
+`
+
+// setupCLI builds instant-db and gives it a fresh home with the plugin script
+// installed as engine, returning the path of the binary
+func setupCLI(t *testing.T, engine, plugin string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("CLI tests use shell script plugins")
+	}
+
+	binDir := t.TempDir()
+	binary := filepath.Join(binDir, "instant-db")
+	build := exec.Command("go", "build", "-o", binary, "../cmd/instantdb")
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build instant-db: %v\n%s", err, output)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, engines.PluginPrefix+engine), []byte(plugin), 0755); err != nil {
+		t.Fatalf("Failed to write plugin: %v", err)
+	}
+
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv(utils.HomeEnv, t.TempDir())
+	return binary
+}
+
+// runCLI runs instant-db and returns its combined output and exit code
+func runCLI(t *testing.T, binary string, args ...string) (string, int) {
+	t.Helper()
+	var output bytes.Buffer
+	cmd := exec.Command(binary, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("Failed to run instant-db: %v", err)
+	}
+	return output.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestRunEnginesAndExitCodes(t *testing.T) {
+	binary := setupCLI(t, "runfake", fakePlugin)
+
+	output, code := runCLI(t, binary, "run", "-e", "runfake", "--", "sh", "-c", `test "$RUNFAKE_URL" = fake://127.0.0.1`)
+	if code != 0 {
+		t.Errorf("Expected the URL in the environment, got exit code %d:\n%s", code, output)
+	}
+
+	output, code = runCLI(t, binary, "run", "-e", "runfake", "-e", "runfake=OTHER_URL", "--", "sh", "-c", `test "$OTHER_URL" = fake://127.0.0.1`)
+	if code != 0 {
+		t.Errorf("Expected -e engine=VAR to name the variable, got exit code %d:\n%s", code, output)
+	}
+
+	output, code = runCLI(t, binary, "run", "-e", "runfake", "-e", "runfake", "--", "true")
+	if code != 1 || !strings.Contains(output, "would both set RUNFAKE_URL") {
+		t.Errorf("Expected duplicate variables to be refused, got exit code %d:\n%s", code, output)
+	}
+	if strings.Contains(output, "Usage:") {
+		t.Errorf("Expected no usage text on failure:\n%s", output)
+	}
+
+	if _, code = runCLI(t, binary, "run", "-e", "runfake", "--", "sh", "-c", "exit 3"); code != 3 {
+		t.Errorf("Expected the command's exit code 3, got %d", code)
+	}
+	if _, code = runCLI(t, binary, "run", "-e", "runfake", "--", "sh", "-c", "kill -TERM $$"); code != 128+int(syscall.SIGTERM) {
+		t.Errorf("Expected exit code %d for a command killed by SIGTERM, got %d", 128+int(syscall.SIGTERM), code)
+	}
+
+	if instances, err := utils.ListInstances(); err != nil || len(instances) != 0 {
+		t.Errorf("Expected every run instance to be removed, got %d (%v)", len(instances), err)
+	}
+}
+
+func TestRunInterruptRemovesInstances(t *testing.T) {
+	binary := setupCLI(t, "runfake", fakePlugin)
+
+	var output bytes.Buffer
+	cmd := exec.Command(binary, "run", "-e", "runfake", "--", "sleep", "30")
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start instant-db: %v", err)
+	}
+
+	// Interrupt once the instance is up
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if instances, _ := utils.ListInstances(); len(instances) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			cmd.Process.Kill()
+			t.Fatalf("The run instance was not started:\n%s", output.String())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	cmd.Process.Signal(os.Interrupt)
+
+	cmd.Wait()
+	if code := cmd.ProcessState.ExitCode(); code != 130 {
+		t.Errorf("Expected exit code 130 after an interrupt, got %d:\n%s", code, output.String())
+	}
+	if instances, err := utils.ListInstances(); err != nil || len(instances) != 0 {
+		t.Errorf("Expected the instance to be removed after an interrupt, got %d (%v)", len(instances), err)
+	}
+}
+
+func TestCloneFailureReleasesReservation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("clone test uses a shell script plugin")
+	}
+	ctx := context.Background()
+
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, engines.PluginPrefix+"clonefake"), []byte(cloneFailPlugin), 0755); err != nil {
+		t.Fatalf("Failed to write plugin: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv(utils.HomeEnv, t.TempDir())
+	engines.DiscoverPlugins()
+
+	def, err := engines.Lookup("clonefake")
+	if err != nil {
+		t.Fatalf("Plugin was not registered: %v", err)
+	}
+	dataDir, _ := utils.DataDir()
+	engine := def.New(dataDir)
+	source, err := engine.Start(ctx, createTestConfig("source", false))
+	if err != nil {
+		t.Fatalf("Failed to start the source: %v", err)
+	}
+	defer cleanupInstance(t, engine, source.ID)
+
+	_, err = engines.Clone(ctx, engine, source, engines.CloneOptions{Name: "copy", Port: 7000})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("Expected the clone to fail to start, got %v", err)
+	}
+
+	instances, err := utils.ListInstances()
+	if err != nil || len(instances) != 1 || instances[0].Name != "source" {
+		t.Fatalf("Expected only the source to be left, got %v (%v)", instances, err)
+	}
+	if entries, _ := os.ReadDir(dataDir); len(entries) != 1 {
+		t.Errorf("Expected the clone's data directory to be removed, got %d directories", len(entries))
+	}
+
+	// The name and port are free again, and the source was resumed
+	err = utils.ReserveInstance(&types.Instance{ID: "other", Name: "copy", Engine: "clonefake", Port: 7000})
+	if err != nil {
+		t.Errorf("Expected the name and port to be released: %v", err)
+	}
+	if saved, err := utils.LoadInstance(source.ID); err != nil || saved.Paused {
+		t.Errorf("Expected the source to be running again, got %+v (%v)", saved, err)
+	}
+}
+
+func TestPluginDiscoveryIsCached(t *testing.T) {
+	countingPlugin := strings.Replace(fakePlugin, "info)   echo", `info)   echo called >> "$INSTANTDB_HOME/info.log"; echo`, 1)
+	binary := setupCLI(t, "cachefake", countingPlugin)
+
+	// A file that is not executable is not a plugin, and not worth a warning
+	pluginDir := filepath.Dir(binary)
+	if err := os.WriteFile(filepath.Join(pluginDir, engines.PluginPrefix+"notes"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	infoCalls := func() int {
+		data, _ := os.ReadFile(filepath.Join(os.Getenv(utils.HomeEnv), "info.log"))
+		return strings.Count(string(data), "called")
+	}
+
+	for i := 0; i < 2; i++ {
+		output, code := runCLI(t, binary, "list")
+		if code != 0 || strings.Contains(output, "notes") {
+			t.Fatalf("Expected list to run without plugin warnings, got exit code %d:\n%s", code, output)
+		}
+	}
+	if calls := infoCalls(); calls != 1 {
+		t.Errorf("Expected the plugin to be asked for its info once, got %d", calls)
+	}
+
+	// Replacing the plugin invalidates its cached info
+	changed := strings.Replace(countingPlugin, "Fake DB", "Fake DB 2", 1)
+	if err := os.WriteFile(filepath.Join(pluginDir, engines.PluginPrefix+"cachefake"), []byte(changed), 0755); err != nil {
+		t.Fatal(err)
+	}
+	runCLI(t, binary, "list")
+	if calls := infoCalls(); calls != 2 {
+		t.Errorf("Expected a changed plugin to be asked again, got %d calls", calls)
+	}
+}
+
+func TestPluginCacheIsNotAnInstanceRecord(t *testing.T) {
+	binary := setupCLI(t, "recordfake", fakePlugin)
+
+	if output, code := runCLI(t, binary, "list"); code != 0 || strings.Contains(output, "corrupted") {
+		t.Fatalf("Expected list to find no corrupted records, got exit code %d:\n%s", code, output)
+	}
+	if _, err := os.Stat(filepath.Join(os.Getenv(utils.HomeEnv), "cache", "plugins.json")); err != nil {
+		t.Fatalf("Expected the plugin cache in the cache directory: %v", err)
+	}
+
+	// prune and doctor refuse to work while CheckInstances reports corruption
+	if corrupt, err := utils.CheckInstances(); err != nil || len(corrupt) != 0 {
+		t.Errorf("Expected no corrupted records, got %v (%v)", corrupt, err)
+	}
+
+	// --home moves the cache along with everything else
+	other := t.TempDir()
+	if output, code := runCLI(t, binary, "--home", other, "list"); code != 0 {
+		t.Fatalf("Failed to list with --home, got exit code %d:\n%s", code, output)
+	}
+	if _, err := os.Stat(filepath.Join(other, "cache", "plugins.json")); err != nil {
+		t.Errorf("Expected --home to hold the plugin cache: %v", err)
+	}
+}
+
+func TestConfigRepairsInvalidSettings(t *testing.T) {
+	binary := setupCLI(t, "configfake", fakePlugin)
+	path, _ := utils.ConfigPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("port_range: abc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if output, code := runCLI(t, binary, "list"); code == 0 || !strings.Contains(output, "port_range") {
+		t.Errorf("Expected list to refuse the invalid port range, got exit code %d:\n%s", code, output)
+	}
+	if output, code := runCLI(t, binary, "config", "list"); code != 0 || !strings.Contains(output, "port_range = abc") {
+		t.Errorf("Expected config list to show the invalid setting, got exit code %d:\n%s", code, output)
+	}
+	if output, code := runCLI(t, binary, "config", "set", "port_range", ""); code != 0 {
+		t.Fatalf("Expected config set to repair the setting, got exit code %d:\n%s", code, output)
+	}
+	if output, code := runCLI(t, binary, "list"); code != 0 {
+		t.Errorf("Expected list to work after the repair, got exit code %d:\n%s", code, output)
+	}
+}
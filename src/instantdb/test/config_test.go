@@ -0,0 +1,63 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+func TestUserConfig(t *testing.T) {
+	t.Setenv(utils.HomeEnv, t.TempDir())
+
+	cfg, err := utils.LoadUserConfig()
+	if err != nil {
+		t.Fatalf("Failed to load missing config: %v", err)
+	}
+
+	settings := map[string]string{
+		"default_engine":            "redis",
+		"persist":                   "true",
+		"port_range":                "52000-52005",
+		"engines.postgres.username": "alice",
+		"engines.redis.version":     "7.0.0",
+	}
+	for key, value := range settings {
+		if err := utils.SetConfigValue(cfg, key, value); err != nil {
+			t.Fatalf("Failed to set %s: %v", key, err)
+		}
+	}
+	if err := utils.SetConfigValue(cfg, "port_range", "9-1"); err == nil {
+		t.Error("Expected an invalid port range to be rejected")
+	}
+	if err := utils.SetConfigValue(cfg, "engines.redis.color", "red"); err == nil {
+		t.Error("Expected an unknown key to be rejected")
+	}
+	if err := utils.SaveUserConfig(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	loaded, err := utils.LoadUserConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	for key, want := range settings {
+		if got, _ := utils.GetConfigValue(loaded, key); got != want {
+			t.Errorf("Expected %s = %q, got %q", key, want, got)
+		}
+	}
+	if values := utils.ConfigValues(loaded); len(values) != len(settings) {
+		t.Errorf("Expected %d values, got %+v", len(settings), values)
+	}
+
+	// Automatically assigned ports stay within the configured range
+	for i := 0; i < 3; i++ {
+		instance := &types.Instance{ID: utils.GenerateID(), Name: utils.GenerateID()}
+		if err := utils.ReserveInstance(instance); err != nil {
+			t.Fatalf("Failed to reserve instance: %v", err)
+		}
+		if instance.Port < 52000 || instance.Port > 52005 {
+			t.Errorf("Port %d is outside the configured range", instance.Port)
+		}
+	}
+}
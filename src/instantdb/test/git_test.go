@@ -0,0 +1,85 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+// writeGitFile writes a file below a fake repository's .git directory
+func writeGitFile(t *testing.T, gitDir, name, content string) {
+	t.Helper()
+	path := filepath.Join(gitDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitBranch(t *testing.T) {
+	repo := t.TempDir()
+	gitDir := filepath.Join(repo, ".git")
+	writeGitFile(t, gitDir, "HEAD", "ref: refs/heads/feature/login\n")
+	writeGitFile(t, gitDir, "refs/heads/feature/login", "0123\n")
+	writeGitFile(t, gitDir, "packed-refs", "# pack-refs with: peeled\n4567 refs/heads/master\n")
+
+	// The branch is found from subdirectories of the repository
+	subdir := filepath.Join(repo, "services", "api")
+	os.MkdirAll(subdir, 0755)
+	branch, err := utils.GitBranch(subdir)
+	if err != nil || branch != "feature/login" {
+		t.Errorf("Expected feature/login, got %q (%v)", branch, err)
+	}
+
+	// Packed branches count, and master is the default without main or origin/HEAD
+	if exists, _ := utils.GitBranchExists(repo, "master"); !exists {
+		t.Error("Expected packed branch master to exist")
+	}
+	if exists, _ := utils.GitBranchExists(repo, "gone"); exists {
+		t.Error("Expected branch gone not to exist")
+	}
+	if branch, _ := utils.GitDefaultBranch(repo); branch != "master" {
+		t.Errorf("Expected default branch master, got %q", branch)
+	}
+	writeGitFile(t, gitDir, "refs/remotes/origin/HEAD", "ref: refs/remotes/origin/develop\n")
+	if branch, _ := utils.GitDefaultBranch(repo); branch != "develop" {
+		t.Errorf("Expected default branch develop from origin/HEAD, got %q", branch)
+	}
+
+	// Only non-default branches get their own instances
+	manifest := &types.Manifest{Dir: repo}
+	if branch, _ := utils.ProjectBranch(manifest); branch != "feature/login" {
+		t.Errorf("Expected project branch feature/login, got %q", branch)
+	}
+	manifest.DefaultBranch = "feature/login"
+	if branch, _ := utils.ProjectBranch(manifest); branch != "" {
+		t.Errorf("Expected no project branch on the default branch, got %q", branch)
+	}
+
+	// Linked worktrees point at their git directory and share the refs
+	worktree := t.TempDir()
+	worktreeGitDir := filepath.Join(gitDir, "worktrees", "wt")
+	writeGitFile(t, worktreeGitDir, "HEAD", "ref: refs/heads/hotfix\n")
+	writeGitFile(t, worktreeGitDir, "commondir", "../..\n")
+	os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+worktreeGitDir+"\n"), 0644)
+	if branch, _ := utils.GitBranch(worktree); branch != "hotfix" {
+		t.Errorf("Expected worktree branch hotfix, got %q", branch)
+	}
+	if exists, _ := utils.GitBranchExists(worktree, "feature/login"); !exists {
+		t.Error("Expected worktree to see the branches of its repository")
+	}
+
+	// Detached heads and directories outside a repository have no branch
+	writeGitFile(t, gitDir, "HEAD", "89abcdef\n")
+	if branch, _ := utils.GitBranch(repo); branch != "" {
+		t.Errorf("Expected no branch for a detached HEAD, got %q", branch)
+	}
+	if branch, err := utils.GitBranch(t.TempDir()); branch != "" || err != nil {
+		t.Errorf("Expected no branch outside a repository, got %q (%v)", branch, err)
+	}
+}
@@ -8,23 +8,27 @@ import (
 
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
 	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
 )
 
 func setupTestEngine(t *testing.T, engineType string) engines.Engine {
-	homeDir, _ := os.UserHomeDir()
-	baseDir := filepath.Join(homeDir, ".instant-db-test", "data")
+	// Keep test instances out of the user's store, unless a test chose its own home
+	if os.Getenv(utils.HomeEnv) == "" {
+		homeDir, _ := os.UserHomeDir()
+		t.Setenv(utils.HomeEnv, filepath.Join(homeDir, ".instant-db-test"))
+	}
+
+	baseDir, err := utils.DataDir()
+	if err != nil {
+		t.Fatalf("Failed to get data directory: %v", err)
+	}
 	
-	var engine engines.Engine
-	switch engineType {
-	case "postgres":
-		engine = engines.NewPostgresEngine(baseDir)
-	case "redis":
-		engine = engines.NewRedisEngine(baseDir)
-	case "mysql":
-		engine = engines.NewMySQLEngine(baseDir)
+	def, err := engines.Lookup(engineType)
+	if err != nil {
+		t.Fatalf("Failed to look up engine: %v", err)
 	}
 	
-	return engine
+	return def.New(baseDir)
 }
 
 func cleanupInstance(t *testing.T, engine engines.Engine, instanceID string) {
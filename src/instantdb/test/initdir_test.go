@@ -0,0 +1,143 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+func TestInitScriptsOrderAndCheck(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20-data.sql.gz", "10-schema.sql", "30-setup.sh", "README.md", ".hidden.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested.sql"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	scripts, skipped, err := engines.InitScripts(dir)
+	if err != nil {
+		t.Fatalf("Failed to list init scripts: %v", err)
+	}
+	var names []string
+	for _, script := range scripts {
+		names = append(names, filepath.Base(script))
+	}
+	if want := []string{"10-schema.sql", "20-data.sql.gz", "30-setup.sh"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected scripts %v, got %v", want, names)
+	}
+	if len(skipped) != 1 || filepath.Base(skipped[0]) != "README.md" {
+		t.Errorf("Expected README.md to be skipped, got %v", skipped)
+	}
+
+	for _, name := range []string{"postgres", "mysql"} {
+		def, _ := engines.Lookup(name)
+		if err := engines.CheckInitScripts(def, def.New(t.TempDir()), scripts); err != nil {
+			t.Errorf("Expected %s to accept SQL init scripts: %v", name, err)
+		}
+	}
+
+	// Redis can seed from files of commands, but does not speak SQL
+	redis, _ := engines.Lookup("redis")
+	if err := engines.CheckInitScripts(redis, redis.New(t.TempDir()), scripts); err == nil {
+		t.Error("Expected redis to refuse SQL init scripts")
+	}
+	if err := engines.CheckInitScripts(redis, redis.New(t.TempDir()), scripts[2:]); err != nil {
+		t.Errorf("Expected redis to accept shell init scripts: %v", err)
+	}
+}
+
+func TestApplyInitScripts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("init script test uses shell scripts")
+	}
+	ctx := context.Background()
+
+	binDir := t.TempDir()
+	pluginPath := filepath.Join(binDir, engines.PluginPrefix+"initdb")
+	if err := os.WriteFile(pluginPath, []byte(fakePlugin), 0755); err != nil {
+		t.Fatalf("Failed to write plugin: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv(utils.HomeEnv, t.TempDir())
+	engines.DiscoverPlugins()
+
+	def, err := engines.Lookup("initdb")
+	if err != nil {
+		t.Fatalf("Plugin was not registered: %v", err)
+	}
+	engine := def.New(t.TempDir())
+	instance, err := engine.Start(ctx, createTestConfig("test-init-scripts", false))
+	if err != nil {
+		t.Fatalf("Failed to start plugin instance: %v", err)
+	}
+	defer cleanupInstance(t, engine, instance.ID)
+
+	// Each script appends to a log in the init directory, the second one through sh
+	dir := t.TempDir()
+	scripts := []string{filepath.Join(dir, "01-first.sh"), filepath.Join(dir, "02-second.sh")}
+	if err := os.WriteFile(scripts[0], []byte("#!/bin/sh\necho \"first $INIT_TEST_VAR\" >> ran.log\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(scripts[1], []byte("echo \"second $INIT_TEST_VAR\" >> ran.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []string
+	env := append(os.Environ(), "INIT_TEST_VAR=set")
+	err = engines.ApplyInitScripts(ctx, engine, instance, scripts, env, func(script string) {
+		progress = append(progress, filepath.Base(script))
+	})
+	if err != nil {
+		t.Fatalf("Failed to apply init scripts: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "ran.log"))
+	if err != nil {
+		t.Fatalf("Init scripts did not run from their directory: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "first set\nsecond set" {
+		t.Errorf("Expected the scripts to run in order with their environment, got %q", got)
+	}
+	if !reflect.DeepEqual(progress, []string{"01-first.sh", "02-second.sh"}) {
+		t.Errorf("Unexpected progress %v", progress)
+	}
+
+	saved, err := utils.LoadInstance(instance.ID)
+	if err != nil {
+		t.Fatalf("Failed to load instance: %v", err)
+	}
+	if !reflect.DeepEqual(saved.InitScripts, scripts) {
+		t.Errorf("Expected the applied scripts to be recorded, got %v", saved.InitScripts)
+	}
+
+	// Resuming the instance leaves the init scripts alone
+	if err := engine.Pause(ctx, instance.ID); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	if err := engine.Resume(ctx, instance.ID); err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	if after, _ := os.ReadFile(filepath.Join(dir, "ran.log")); string(after) != string(data) {
+		t.Errorf("Init scripts ran again on resume: %q", after)
+	}
+
+	// A failing script stops the rest
+	failing := filepath.Join(dir, "03-fail.sh")
+	if err := os.WriteFile(failing, []byte("exit 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = engines.ApplyInitScripts(ctx, engine, instance, []string{failing, scripts[0]}, env, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "03-fail.sh") {
+		t.Errorf("Expected the failing script to be reported, got %v", err)
+	}
+}
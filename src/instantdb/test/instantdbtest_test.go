@@ -0,0 +1,25 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/pkg/instantdbtest"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestInstantDBTestRedis(t *testing.T) {
+	ctx := context.Background()
+
+	opts, err := redis.ParseURL(instantdbtest.Redis(t))
+	if err != nil {
+		t.Fatalf("Failed to parse connection URL: %v", err)
+	}
+
+	client := redis.NewClient(opts)
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatalf("Failed to ping redis: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+)
+
+func TestRotateLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "redis.log")
+
+	// Small logs are left alone
+	os.WriteFile(path, []byte("small\n"), 0644)
+	if err := engines.RotateLog(path); err != nil {
+		t.Fatalf("Failed to rotate log: %v", err)
+	}
+	if files := engines.LogFiles(path); len(files) != 1 {
+		t.Errorf("Expected no rotated logs, got %v", files)
+	}
+
+	// Large logs are rotated until LogKeep old ones exist
+	for i := 1; i <= engines.LogKeep+1; i++ {
+		content := fmt.Sprintf("generation %d\n", i) + strings.Repeat("x", engines.LogMaxSize)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := engines.RotateLog(path); err != nil {
+			t.Fatalf("Failed to rotate log: %v", err)
+		}
+	}
+
+	files := engines.LogFiles(path)
+	if len(files) != engines.LogKeep+1 || files[len(files)-1] != path {
+		t.Fatalf("Expected %d rotated logs before the current one, got %v", engines.LogKeep, files)
+	}
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Errorf("Expected the current log to be truncated, got %d bytes", info.Size())
+	}
+	newest, _ := os.ReadFile(path + ".1")
+	if !strings.HasPrefix(string(newest), fmt.Sprintf("generation %d\n", engines.LogKeep+1)) {
+		t.Error("Expected the last rotation in log.1")
+	}
+	oldest, _ := os.ReadFile(files[0])
+	if !strings.HasPrefix(string(oldest), "generation 2\n") {
+		t.Error("Expected the first rotation to have been dropped")
+	}
+}
+
+func TestLogTime(t *testing.T) {
+	expected := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+	lines := []string{
+		"2024-03-09 14:05:06.123 UTC [4242] LOG:  database system is ready to accept connections",
+		"2024-03-09T14:05:06.123456Z 0 [System] [MY-010931] [Server] /usr/sbin/mysqld: ready for connections.",
+	}
+	for _, line := range lines {
+		logged, ok := engines.LogTime(line)
+		if !ok || !logged.Truncate(time.Second).Equal(expected) {
+			t.Errorf("Expected %v for %q, got %v (%v)", expected, line, logged, ok)
+		}
+	}
+
+	// Redis logs in local time
+	logged, ok := engines.LogTime("4242:M 09 Mar 2024 14:05:06.123 * Ready to accept connections tcp")
+	local := time.Date(2024, 3, 9, 14, 5, 6, 0, time.Local)
+	if !ok || !logged.Truncate(time.Second).Equal(local) {
+		t.Errorf("Expected %v for the redis line, got %v (%v)", local, logged, ok)
+	}
+
+	if _, ok := engines.LogTime("\tcontinued detail of the previous line"); ok {
+		t.Error("Expected no time for a continuation line")
+	}
+}
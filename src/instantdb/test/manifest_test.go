@@ -0,0 +1,106 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+const testManifest = `instances:
+  db:
+    engine: postgres
+    version: "14"
+    port: 5433
+    seed: [schema.sql, /abs/seed.sql]
+    persist: true
+  cache:
+    engine: redis
+`
+
+func TestLoadManifest(t *testing.T) {
+	t.Setenv(utils.HomeEnv, t.TempDir())
+	root := filepath.Join(t.TempDir(), "shop")
+	nested := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, utils.ManifestFileName), []byte(testManifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	path, err := utils.FindManifest(nested)
+	if err != nil {
+		t.Fatalf("Manifest was not found from a subdirectory: %v", err)
+	}
+
+	manifest, err := utils.LoadManifest(path)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	if manifest.Project != "shop" || manifest.Dir != root {
+		t.Errorf("Unexpected project %q in %q", manifest.Project, manifest.Dir)
+	}
+
+	db := manifest.Instances["db"]
+	if db.Engine != "postgres" || db.Version != "14" || db.Port != 5433 || !db.Persist {
+		t.Errorf("Unexpected instance %+v", db)
+	}
+	if len(db.Seed) != 2 || db.Seed[0] != filepath.Join(root, "schema.sql") || db.Seed[1] != "/abs/seed.sql" {
+		t.Errorf("Seed paths were not resolved against the manifest: %v", db.Seed)
+	}
+	if name := utils.ProjectInstanceName(manifest, "db"); name != "shop/db" {
+		t.Errorf("Expected namespaced name shop/db, got %q", name)
+	}
+
+	// Entries without an engine are rejected
+	broken := filepath.Join(t.TempDir(), utils.ManifestFileName)
+	os.WriteFile(broken, []byte("instances:\n  db:\n    port: 1\n"), 0644)
+	if _, err := utils.LoadManifest(broken); err == nil {
+		t.Error("Expected a manifest entry without engine to be rejected")
+	}
+
+	if _, err := utils.FindManifest(t.TempDir()); err == nil {
+		t.Error("Expected no manifest outside of a project")
+	}
+}
+
+func TestManifestProjectNameCollision(t *testing.T) {
+	t.Setenv(utils.HomeEnv, t.TempDir())
+
+	// Two checkouts of the same repository in different places
+	first := filepath.Join(t.TempDir(), "api")
+	second := filepath.Join(t.TempDir(), "api")
+	for _, dir := range []string{first, second} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, utils.ManifestFileName), []byte("instances:\n  db:\n    engine: postgres\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifest, err := utils.LoadManifest(filepath.Join(first, utils.ManifestFileName))
+	if err != nil || manifest.Project != "api" {
+		t.Fatalf("Expected project api, got %v (%v)", manifest, err)
+	}
+	err = utils.ReserveInstance(&types.Instance{
+		ID: "first", Name: utils.ProjectInstanceName(manifest, "db"), Engine: "postgres", Port: 1, Project: first,
+	})
+	if err != nil {
+		t.Fatalf("Failed to reserve instance: %v", err)
+	}
+
+	other, err := utils.LoadManifest(filepath.Join(second, utils.ManifestFileName))
+	if err != nil || other.Project != "api-2" {
+		t.Fatalf("Expected the second checkout to become api-2, got %v (%v)", other, err)
+	}
+
+	// The first checkout keeps its name
+	manifest, err = utils.LoadManifest(filepath.Join(first, utils.ManifestFileName))
+	if err != nil || manifest.Project != "api" {
+		t.Errorf("Expected the first checkout to stay api, got %v (%v)", manifest, err)
+	}
+}
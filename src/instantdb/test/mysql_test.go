@@ -1,13 +1,18 @@
 package test
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -77,9 +82,20 @@ func TestMySQLPauseResume(t *testing.T) {
 	
 	time.Sleep(3 * time.Second)
 	
+	// Resuming a running instance must not start a second server
+	if err := engine.Resume(ctx, instance.ID); err == nil {
+		t.Error("Expected resuming a running instance to fail")
+	}
+	if saved, err := utils.LoadInstance(instance.ID); err != nil || saved.PID != instance.PID {
+		t.Errorf("Expected the server PID %d to be kept, got %+v (%v)", instance.PID, saved, err)
+	}
+	
 	if err := engine.Pause(ctx, instance.ID); err != nil {
 		t.Fatalf("Failed to pause: %v", err)
 	}
+	if err := engine.Pause(ctx, instance.ID); err == nil {
+		t.Error("Expected pausing a paused instance to fail")
+	}
 	
 	status, err := engine.Status(ctx, instance.ID)
 	if err != nil {
@@ -130,3 +146,95 @@ func TestMySQLPersistence(t *testing.T) {
 	
 	t.Log("Persistence test passed")
 }
+
+func TestMySQLDumpRestore(t *testing.T) {
+	ctx := context.Background()
+	engine := setupTestEngine(t, "mysql")
+	dumper := engine.(engines.Dumper)
+
+	source, err := engine.Start(ctx, createTestConfig("test-mysql-dump", false))
+	if err != nil {
+		t.Fatalf("Failed to start mysql: %v", err)
+	}
+	defer cleanupInstance(t, engine, source.ID)
+
+	open := func(instance *types.Instance) *sql.DB {
+		dsn := fmt.Sprintf("%s:%s@tcp(127.0.0.1:%d)/?multiStatements=true", instance.Username, instance.Password, instance.Port)
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			t.Fatalf("Failed to open connection: %v", err)
+		}
+		return db
+	}
+	db := open(source)
+	defer db.Close()
+
+	schema := `CREATE DATABASE shop;
+USE shop;
+CREATE TABLE customers (id int AUTO_INCREMENT PRIMARY KEY, name varchar(100) NOT NULL, avatar blob,
+	upper_name varchar(100) AS (UPPER(name)) VIRTUAL);
+CREATE TABLE orders (id int AUTO_INCREMENT PRIMARY KEY, customer_id int, note text,
+	FOREIGN KEY (customer_id) REFERENCES customers (id));
+CREATE VIEW order_notes AS SELECT note FROM orders;
+INSERT INTO customers (name, avatar) VALUES ('O''Brien', 0x00FF1A5C27), ('Nobody', NULL);
+INSERT INTO orders (customer_id, note) VALUES (1, 'a;b\\c\n"quoted"\r\0end'), (1, NULL);`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	var dump bytes.Buffer
+	if err := dumper.Dump(ctx, source.ID, engines.DumpFormatSQL, &dump); err != nil {
+		t.Fatalf("Failed to dump: %v", err)
+	}
+	if strings.Contains(dump.String(), "DEFINER=") {
+		t.Error("Expected the view definer to be left out of the dump")
+	}
+
+	target, err := engine.Start(ctx, createTestConfig("test-mysql-restore", false))
+	if err != nil {
+		t.Fatalf("Failed to start mysql: %v", err)
+	}
+	defer cleanupInstance(t, engine, target.ID)
+
+	if err := dumper.Load(ctx, target.ID, engines.DumpFormatSQL, bytes.NewReader(dump.Bytes()), func(done, total int) {}); err != nil {
+		t.Fatalf("Failed to restore: %v\n%s", err, dump.String())
+	}
+
+	restored := open(target)
+	defer restored.Close()
+
+	var name, upper string
+	var avatar []byte
+	err = restored.QueryRow("SELECT name, upper_name, avatar FROM shop.customers WHERE id = 1").Scan(&name, &upper, &avatar)
+	if err != nil || name != "O'Brien" || upper != "O'BRIEN" || !bytes.Equal(avatar, []byte{0x00, 0xff, 0x1a, 0x5c, 0x27}) {
+		t.Errorf("Expected the restored customer with its avatar, got %q %q %x (%v)", name, upper, avatar, err)
+	}
+	var note string
+	if err := restored.QueryRow("SELECT note FROM shop.order_notes WHERE note IS NOT NULL").Scan(&note); err != nil || note != "a;b\\c\n\"quoted\"\r\x00end" {
+		t.Errorf("Expected the restored note, got %q (%v)", note, err)
+	}
+
+	// Auto increment continues where the source left off
+	result, err := restored.Exec("INSERT INTO shop.customers (name) VALUES ('New')")
+	if err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+	if id, _ := result.LastInsertId(); id != 3 {
+		t.Errorf("Expected the next customer id to be 3, got %d", id)
+	}
+	if _, err := restored.Exec("INSERT INTO shop.orders (customer_id) VALUES (42)"); err == nil {
+		t.Error("Expected the foreign key to be restored")
+	}
+
+	// The dump replaces what it contains when restored over its own instance
+	if _, err := db.Exec("INSERT INTO shop.customers (name) VALUES ('Later')"); err != nil {
+		t.Fatalf("Failed to change source: %v", err)
+	}
+	if err := dumper.Load(ctx, source.ID, engines.DumpFormatSQL, bytes.NewReader(dump.Bytes()), func(done, total int) {}); err != nil {
+		t.Fatalf("Failed to restore over the source: %v", err)
+	}
+	var customers int
+	if err := db.QueryRow("SELECT count(*) FROM shop.customers").Scan(&customers); err != nil || customers != 2 {
+		t.Errorf("Expected 2 customers after restoring over the source, got %d (%v)", customers, err)
+	}
+}
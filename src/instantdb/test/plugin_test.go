@@ -0,0 +1,77 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+const fakePlugin = `#!/bin/sh
+case "$1" in
+info)   echo '{"info":{"display_name":"Fake DB","default_username":"fake"}}' ;;
+start)  echo '{"pid":0}' ;;
+status) echo '{"status":{"running":true,"healthy":true,"message":"ok"}}' ;;
+url)    echo '{"url":"fake://127.0.0.1"}' ;;
+fail)   echo '{"error":"boom"}'; exit 1 ;;
+*)      echo '{}' ;;
+esac
+`
+
+func TestPluginDiscoveryAndLifecycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test uses a shell script")
+	}
+	ctx := context.Background()
+
+	binDir := t.TempDir()
+	pluginPath := filepath.Join(binDir, engines.PluginPrefix+"fakedb")
+	if err := os.WriteFile(pluginPath, []byte(fakePlugin), 0755); err != nil {
+		t.Fatalf("Failed to write plugin: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("HOME", t.TempDir())
+
+	for _, err := range engines.DiscoverPlugins() {
+		t.Fatalf("Unexpected discovery warning: %v", err)
+	}
+
+	def, err := engines.Lookup("fakedb")
+	if err != nil {
+		t.Fatalf("Plugin was not registered: %v", err)
+	}
+	if def.DisplayName != "Fake DB" || def.DefaultUsername != "fake" {
+		t.Errorf("Plugin info not applied: %+v", def)
+	}
+
+	engine := def.New(t.TempDir())
+	instance, err := engine.Start(ctx, createTestConfig("test-plugin", false))
+	if err != nil {
+		t.Fatalf("Failed to start plugin instance: %v", err)
+	}
+
+	if _, err := utils.LoadInstance(instance.ID); err != nil {
+		t.Fatalf("Plugin instance was not saved: %v", err)
+	}
+
+	url, err := engine.GetConnectionURL(instance.ID)
+	if err != nil || url != "fake://127.0.0.1" {
+		t.Errorf("Unexpected connection URL %q: %v", url, err)
+	}
+
+	status, err := engine.Status(ctx, instance.ID)
+	if err != nil || !status.Running {
+		t.Errorf("Unexpected status %+v: %v", status, err)
+	}
+
+	if err := engine.Stop(ctx, instance.ID); err != nil {
+		t.Fatalf("Failed to stop plugin instance: %v", err)
+	}
+	if _, err := utils.LoadInstance(instance.ID); err == nil {
+		t.Error("Instance metadata should be removed after stop")
+	}
+}
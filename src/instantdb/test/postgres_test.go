@@ -1,12 +1,18 @@
 package test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"database/sql"
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
 	_ "github.com/lib/pq"
 )
 
@@ -126,3 +132,251 @@ func TestPostgresPersistence(t *testing.T) {
 	
 	t.Log("Persistence test passed")
 }
+
+func TestPostgresWithData(t *testing.T) {
+	ctx := context.Background()
+	engine := setupTestEngine(t, "postgres")
+
+	// Scripts of a directory run in lexical order, gzipped ones included
+	dataDir := t.TempDir()
+	schema := "CREATE TABLE items (name text);\n-- a comment; with a semicolon\n"
+	if err := os.WriteFile(filepath.Join(dataDir, "01-schema.sql"), []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filepath.Join(dataDir, "02-data.sql.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	gz.Write([]byte("INSERT INTO items VALUES ('a;b');\nINSERT INTO items VALUES ('c');\n"))
+	gz.Close()
+	file.Close()
+
+	// pg_dump output: escape strings and COPY data blocks
+	dump := "INSERT INTO items VALUES (E'it\\'s;');\nCOPY public.items (name) FROM stdin;\nd\\te\n\\N\n\\.\nINSERT INTO items VALUES ('f');\n"
+	if err := os.WriteFile(filepath.Join(dataDir, "03-dump.sql"), []byte(dump), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := createTestConfig("test-postgres-data", false)
+	config.WithData = dataDir
+	instance, err := engine.Start(ctx, config)
+	if err != nil {
+		t.Fatalf("Failed to start postgres: %v", err)
+	}
+	defer cleanupInstance(t, engine, instance.ID)
+
+	connURL, err := engine.GetConnectionURL(instance.ID)
+	if err != nil {
+		t.Fatalf("Failed to get connection URL: %v", err)
+	}
+	db, err := sql.Open("postgres", connURL)
+	if err != nil {
+		t.Fatalf("Failed to open connection: %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT count(*) FROM items").Scan(&count); err != nil {
+		t.Fatalf("Failed to query seeded table: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("Expected 6 seeded rows, got %d", count)
+	}
+	if err := db.QueryRow("SELECT count(*) FROM items WHERE name IN ('it''s;', E'd\\te') OR name IS NULL").Scan(&count); err != nil || count != 3 {
+		t.Errorf("Expected the escape string and COPY rows to be seeded, got %d (%v)", count, err)
+	}
+}
+
+func TestPostgresWithDataError(t *testing.T) {
+	ctx := context.Background()
+	engine := setupTestEngine(t, "postgres")
+
+	script := filepath.Join(t.TempDir(), "broken.sql")
+	content := "CREATE TABLE items (name text);\n\nINSERT INTO missing VALUES (1);\n"
+	if err := os.WriteFile(script, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := createTestConfig("test-postgres-data-error", false)
+	config.WithData = script
+	instance, err := engine.Start(ctx, config)
+	if err == nil {
+		cleanupInstance(t, engine, instance.ID)
+		t.Fatal("Expected start to fail on the broken script")
+	}
+
+	var seedErr *engines.SeedError
+	if !errors.As(err, &seedErr) {
+		t.Fatalf("Expected a seed error, got %v", err)
+	}
+	if seedErr.Line != 3 || seedErr.Statement != "INSERT INTO missing VALUES (1)" {
+		t.Errorf("Expected line 3 and the failing statement, got line %d: %q", seedErr.Line, seedErr.Statement)
+	}
+}
+
+func TestPostgresDumpRestore(t *testing.T) {
+	ctx := context.Background()
+	engine := setupTestEngine(t, "postgres")
+	dumper := engine.(engines.Dumper)
+
+	source, err := engine.Start(ctx, createTestConfig("test-postgres-dump", false))
+	if err != nil {
+		t.Fatalf("Failed to start postgres: %v", err)
+	}
+	defer cleanupInstance(t, engine, source.ID)
+
+	sourceURL, _ := engine.GetConnectionURL(source.ID)
+	db, err := sql.Open("postgres", sourceURL)
+	if err != nil {
+		t.Fatalf("Failed to open connection: %v", err)
+	}
+	defer db.Close()
+
+	schema := `CREATE TYPE mood AS ENUM ('happy', 'sad');
+CREATE TABLE authors (id serial PRIMARY KEY, name text NOT NULL, mood mood);
+CREATE TABLE books (id int GENERATED ALWAYS AS IDENTITY PRIMARY KEY, author_id int REFERENCES authors (id), title text);
+CREATE INDEX books_title ON books (title);
+CREATE VIEW book_titles AS SELECT title FROM books;
+INSERT INTO authors (name, mood) VALUES ('O''Brien', 'happy'), ('Nobody', NULL);
+INSERT INTO books (author_id, title) VALUES (1, 'a;b'), (1, NULL);
+CREATE DOMAIN positive AS int CHECK (VALUE > 0);
+CREATE TYPE address AS (street text, city text);
+CREATE TABLE shops (id positive, address address);
+INSERT INTO shops VALUES (1, ROW('Main St', 'Springfield'));`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	var dump bytes.Buffer
+	if err := dumper.Dump(ctx, source.ID, engines.DumpFormatSQL, &dump); err != nil {
+		t.Fatalf("Failed to dump: %v", err)
+	}
+
+	target, err := engine.Start(ctx, createTestConfig("test-postgres-restore", false))
+	if err != nil {
+		t.Fatalf("Failed to start postgres: %v", err)
+	}
+	defer cleanupInstance(t, engine, target.ID)
+
+	if err := dumper.Load(ctx, target.ID, engines.DumpFormatSQL, bytes.NewReader(dump.Bytes()), func(done, total int) {}); err != nil {
+		t.Fatalf("Failed to restore: %v\n%s", err, dump.String())
+	}
+
+	// The dump replaces what it contains when restored over its own instance
+	if _, err := db.Exec("INSERT INTO authors (name) VALUES ('Later')"); err != nil {
+		t.Fatalf("Failed to change source: %v", err)
+	}
+	if err := dumper.Load(ctx, source.ID, engines.DumpFormatSQL, bytes.NewReader(dump.Bytes()), func(done, total int) {}); err != nil {
+		t.Fatalf("Failed to restore over the source: %v", err)
+	}
+	var authors int
+	if err := db.QueryRow("SELECT count(*) FROM authors").Scan(&authors); err != nil || authors != 2 {
+		t.Errorf("Expected 2 authors after restoring over the source, got %d (%v)", authors, err)
+	}
+
+	targetURL, _ := engine.GetConnectionURL(target.ID)
+	restored, err := sql.Open("postgres", targetURL)
+	if err != nil {
+		t.Fatalf("Failed to open connection: %v", err)
+	}
+	defer restored.Close()
+
+	var name string
+	if err := restored.QueryRow("SELECT name FROM authors WHERE mood = 'happy'").Scan(&name); err != nil || name != "O'Brien" {
+		t.Errorf("Expected restored author O'Brien, got %q (%v)", name, err)
+	}
+	var titles int
+	if err := restored.QueryRow("SELECT count(*) FROM book_titles WHERE title IS NULL OR title = 'a;b'").Scan(&titles); err != nil || titles != 2 {
+		t.Errorf("Expected 2 restored books, got %d (%v)", titles, err)
+	}
+
+	// Sequences continue where the source left off
+	var id int
+	if err := restored.QueryRow("INSERT INTO authors (name) VALUES ('New') RETURNING id").Scan(&id); err != nil || id != 3 {
+		t.Errorf("Expected the next author id to be 3, got %d (%v)", id, err)
+	}
+	if err := restored.QueryRow("INSERT INTO books (author_id) VALUES (3) RETURNING id").Scan(&id); err != nil || id != 3 {
+		t.Errorf("Expected the next book id to be 3, got %d (%v)", id, err)
+	}
+	if _, err := restored.Exec("INSERT INTO books (author_id) VALUES (42)"); err == nil {
+		t.Error("Expected the foreign key to be restored")
+	}
+
+	// Domains and composite types come along with the tables using them
+	var city string
+	if err := restored.QueryRow("SELECT (address).city FROM shops WHERE id = 1").Scan(&city); err != nil || city != "Springfield" {
+		t.Errorf("Expected the restored shop in Springfield, got %q (%v)", city, err)
+	}
+	if _, err := restored.Exec("INSERT INTO shops (id) VALUES (0)"); err == nil {
+		t.Error("Expected the domain check to be restored")
+	}
+
+	// Partitioned tables cannot be dumped, rather than losing their rows
+	if _, err := db.Exec("CREATE TABLE events (at date) PARTITION BY RANGE (at)"); err != nil {
+		t.Fatalf("Failed to create partitioned table: %v", err)
+	}
+	err = dumper.Dump(ctx, source.ID, engines.DumpFormatSQL, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "events") {
+		t.Errorf("Expected the partitioned table to be refused, got %v", err)
+	}
+}
+
+func TestPostgresQuerySession(t *testing.T) {
+	ctx := context.Background()
+	engine := setupTestEngine(t, "postgres")
+
+	instance, err := engine.Start(ctx, createTestConfig("test-postgres-query", false))
+	if err != nil {
+		t.Fatalf("Failed to start postgres: %v", err)
+	}
+	defer cleanupInstance(t, engine, instance.ID)
+
+	session, err := engine.(engines.Querier).OpenQuery(ctx, instance.ID)
+	if err != nil {
+		t.Fatalf("Failed to open query session: %v", err)
+	}
+	defer session.Close()
+
+	// Statements are complete at a semicolon outside of quotes
+	if _, complete := session.Split("CREATE TABLE notes (id int, body text);\nINSERT INTO notes VALUES (1, 'a;\n", false); complete {
+		t.Error("Expected an open quote to need more input")
+	}
+	statements, complete := session.Split("CREATE TABLE notes (id int, body text);\nINSERT INTO notes VALUES (1, 'a;\nb'), (2, NULL);\n", false)
+	if !complete || len(statements) != 2 {
+		t.Fatalf("Expected 2 complete statements, got %q (%v)", statements, complete)
+	}
+	for _, statement := range statements {
+		if _, err := session.Run(ctx, statement); err != nil {
+			t.Fatalf("Failed to run %q: %v", statement, err)
+		}
+	}
+
+	result, err := session.Run(ctx, "UPDATE notes SET id = id + 10")
+	if err != nil || result.Status != "2 rows affected" {
+		t.Errorf("Expected 2 rows affected, got %+v (%v)", result, err)
+	}
+	result, err = session.Run(ctx, "SELECT id, body FROM notes ORDER BY id")
+	if err != nil || len(result.Rows) != 2 || result.Rows[0][1].String != "a;\nb" || result.Rows[1][1].Valid {
+		t.Errorf("Expected the rows with a NULL body, got %+v (%v)", result, err)
+	}
+
+	// Session state carries over between statements
+	session.Run(ctx, "SET search_path TO pg_catalog")
+	if result, err := session.Run(ctx, "SHOW search_path"); err != nil || result.Rows[0][0].String != "pg_catalog" {
+		t.Errorf("Expected the search path to persist, got %+v (%v)", result, err)
+	}
+	session.Run(ctx, "RESET search_path")
+
+	tables, err := session.Tables(ctx, "no*")
+	if err != nil || len(tables.Rows) != 1 || tables.Rows[0][1].String != "notes" {
+		t.Errorf("Expected the notes table, got %+v (%v)", tables, err)
+	}
+	columns, err := session.Describe(ctx, "public.notes")
+	if err != nil || len(columns.Rows) != 2 || columns.Rows[1][0].String != "body" {
+		t.Errorf("Expected the columns of notes, got %+v (%v)", columns, err)
+	}
+	if _, err := session.Describe(ctx, "missing"); err == nil {
+		t.Error("Expected describing a missing table to fail")
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+func TestTerminateProcessEscalates(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script")
+	}
+
+	// A server that ignores SIGTERM, with a child in its process group
+	cmd := exec.Command("sh", "-c", `trap "" TERM; sleep 30 & wait`)
+	utils.SetProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start process: %v", err)
+	}
+	go cmd.Wait()
+
+	pid := cmd.Process.Pid
+	pgid := utils.ProcessGroup(pid)
+	if pgid != pid {
+		t.Fatalf("Expected process to lead its group, got pgid %d", pgid)
+	}
+
+	start := time.Now()
+	if err := utils.TerminateProcess(pid, pgid, 500*time.Millisecond); err != nil {
+		t.Fatalf("Failed to terminate process: %v", err)
+	}
+	if utils.IsProcessRunning(pid) {
+		t.Error("Process is still running")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Termination took too long: %s", time.Since(start))
+	}
+
+	// Stopping a process that is already gone is not an error
+	if err := utils.TerminateProcess(pid, pgid, 500*time.Millisecond); err != nil {
+		t.Errorf("Expected no error for exited process, got %v", err)
+	}
+}
+
+func TestMentionsPath(t *testing.T) {
+	path := "/home/me/.instant-db/data/abc"
+	cases := map[string]bool{
+		"postgres -D /home/me/.instant-db/data/abc":                  true,
+		"mysqld --datadir=/home/me/.instant-db/data/abc --port=5":    true,
+		"redis-server /home/me/.instant-db/data/abc/redis.conf":      true,
+		`sh -c "cd '/home/me/.instant-db/data/abc' && run"`:          true,
+		"postgres -D /home/me/.instant-db/data/abcdef":               false,
+		"mysqld --datadir=/home/me/.instant-db/data/abc-2":           false,
+		"postgres -D /other/home/me/.instant-db/data/abc":            false,
+		"postgres -D /home/me/.instant-db/data/abcdef /tmp/data/abc": false,
+	}
+	for line, want := range cases {
+		if got := utils.MentionsPath(line, path); got != want {
+			t.Errorf("MentionsPath(%q) = %v, want %v", line, got, want)
+		}
+	}
+
+	// A trailing separator on the path does not matter
+	if !utils.MentionsPath("postgres -D /home/me/.instant-db/data/abc", path+"/") {
+		t.Error("Expected a path with a trailing separator to match")
+	}
+}
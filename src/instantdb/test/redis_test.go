@@ -1,12 +1,16 @@
 package test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -82,9 +86,20 @@ func TestRedisPauseResume(t *testing.T) {
 	
 	time.Sleep(1 * time.Second)
 	
+	// Resuming a running instance must not start a second server
+	if err := engine.Resume(ctx, instance.ID); err == nil {
+		t.Error("Expected resuming a running instance to fail")
+	}
+	if saved, err := utils.LoadInstance(instance.ID); err != nil || saved.PID != instance.PID {
+		t.Errorf("Expected the server PID %d to be kept, got %+v (%v)", instance.PID, saved, err)
+	}
+	
 	if err := engine.Pause(ctx, instance.ID); err != nil {
 		t.Fatalf("Failed to pause: %v", err)
 	}
+	if err := engine.Pause(ctx, instance.ID); err == nil {
+		t.Error("Expected pausing a paused instance to fail")
+	}
 	
 	status, err := engine.Status(ctx, instance.ID)
 	if err != nil {
@@ -135,3 +150,139 @@ func TestRedisPersistence(t *testing.T) {
 	
 	t.Log("Persistence test passed")
 }
+
+func TestRedisWithData(t *testing.T) {
+	ctx := context.Background()
+	engine := setupTestEngine(t, "redis")
+
+	commands := filepath.Join(t.TempDir(), "seed.redis")
+	content := "# fixtures\nSET greeting \"hello world\"\nRPUSH queue a b c\n"
+	if err := os.WriteFile(commands, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := createTestConfig("test-redis-data", false)
+	config.WithData = commands
+	instance, err := engine.Start(ctx, config)
+	if err != nil {
+		t.Fatalf("Failed to start redis: %v", err)
+	}
+	defer cleanupInstance(t, engine, instance.ID)
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("localhost:%d", instance.Port),
+		Password: instance.Password,
+	})
+	defer client.Close()
+
+	if value, err := client.Get(ctx, "greeting").Result(); err != nil || value != "hello world" {
+		t.Errorf("Expected seeded greeting, got %q (%v)", value, err)
+	}
+	if length, err := client.LLen(ctx, "queue").Result(); err != nil || length != 3 {
+		t.Errorf("Expected 3 queued items, got %d (%v)", length, err)
+	}
+}
+
+func TestRedisDumpRestoreJSON(t *testing.T) {
+	ctx := context.Background()
+	engine := setupTestEngine(t, "redis")
+	dumper := engine.(engines.Dumper)
+
+	source, err := engine.Start(ctx, createTestConfig("test-redis-dump", false))
+	if err != nil {
+		t.Fatalf("Failed to start redis: %v", err)
+	}
+	defer cleanupInstance(t, engine, source.ID)
+
+	client := redis.NewClient(&redis.Options{Addr: fmt.Sprintf("localhost:%d", source.Port), Password: source.Password})
+	defer client.Close()
+	client.Set(ctx, "greeting", "hello", time.Hour)
+	client.Set(ctx, "binary", "\xff\x00", 0)
+	client.RPush(ctx, "queue", "a", "b")
+	client.HSet(ctx, "user", "name", "ada")
+	client.ZAdd(ctx, "scores", redis.Z{Member: "ada", Score: 1.5})
+
+	var dump bytes.Buffer
+	if err := dumper.Dump(ctx, source.ID, engines.DumpFormatJSON, &dump); err != nil {
+		t.Fatalf("Failed to dump: %v", err)
+	}
+
+	target, err := engine.Start(ctx, createTestConfig("test-redis-restore", false))
+	if err != nil {
+		t.Fatalf("Failed to start redis: %v", err)
+	}
+	defer cleanupInstance(t, engine, target.ID)
+
+	if err := dumper.Load(ctx, target.ID, engines.DumpFormatJSON, &dump, func(done, total int) {}); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+
+	restored := redis.NewClient(&redis.Options{Addr: fmt.Sprintf("localhost:%d", target.Port), Password: target.Password})
+	defer restored.Close()
+	if value, _ := restored.Get(ctx, "binary").Result(); value != "\xff\x00" {
+		t.Errorf("Expected binary value to survive, got %q", value)
+	}
+	if ttl, _ := restored.TTL(ctx, "greeting").Result(); ttl <= 0 {
+		t.Errorf("Expected greeting to keep its expiry, got %v", ttl)
+	}
+	if items, _ := restored.LRange(ctx, "queue", 0, -1).Result(); len(items) != 2 || items[0] != "a" {
+		t.Errorf("Expected queue [a b], got %v", items)
+	}
+	if name, _ := restored.HGet(ctx, "user", "name").Result(); name != "ada" {
+		t.Errorf("Expected hash field ada, got %q", name)
+	}
+	if score, _ := restored.ZScore(ctx, "scores", "ada").Result(); score != 1.5 {
+		t.Errorf("Expected score 1.5, got %v", score)
+	}
+}
+
+func TestRedisQuerySession(t *testing.T) {
+	ctx := context.Background()
+	engine := setupTestEngine(t, "redis")
+
+	instance, err := engine.Start(ctx, createTestConfig("test-redis-query", false))
+	if err != nil {
+		t.Fatalf("Failed to start redis: %v", err)
+	}
+	defer cleanupInstance(t, engine, instance.ID)
+
+	session, err := engine.(engines.Querier).OpenQuery(ctx, instance.ID)
+	if err != nil {
+		t.Fatalf("Failed to open query session: %v", err)
+	}
+	defer session.Close()
+
+	// A quoted argument may span lines
+	if _, complete := session.Split("SET note \"first\n", false); complete {
+		t.Error("Expected an open quote to need more input")
+	}
+	commands, complete := session.Split("SET note \"first\nsecond\"\nRPUSH queue a b\n", false)
+	if !complete || len(commands) != 2 {
+		t.Fatalf("Expected 2 complete commands, got %q (%v)", commands, complete)
+	}
+	for _, command := range commands {
+		if _, err := session.Run(ctx, command); err != nil {
+			t.Fatalf("Failed to run %q: %v", command, err)
+		}
+	}
+
+	result, err := session.Run(ctx, "LRANGE queue 0 -1")
+	if err != nil || len(result.Rows) != 2 || result.Rows[1][1].String != "b" {
+		t.Errorf("Expected the list elements as rows, got %+v (%v)", result, err)
+	}
+	result, err = session.Run(ctx, "GET note")
+	if err != nil || len(result.Rows) != 1 || result.Rows[0][0].String != "first\nsecond" {
+		t.Errorf("Expected the multi-line value, got %+v (%v)", result, err)
+	}
+	if result, err := session.Run(ctx, "GET missing"); err != nil || result.Status != "(nil)" {
+		t.Errorf("Expected (nil) for a missing key, got %+v (%v)", result, err)
+	}
+
+	tables, err := session.Tables(ctx, "qu*")
+	if err != nil || len(tables.Rows) != 1 || tables.Rows[0][1].String != "list" {
+		t.Errorf("Expected the queue key as a list, got %+v (%v)", tables, err)
+	}
+	if _, err := session.Describe(ctx, "missing"); err == nil {
+		t.Error("Expected describing a missing key to fail")
+	}
+}
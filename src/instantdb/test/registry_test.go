@@ -0,0 +1,38 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+)
+
+func TestEngineRegistryLookup(t *testing.T) {
+	for _, name := range []string{"postgres", "mysql", "redis"} {
+		def, err := engines.Lookup(name)
+		if err != nil {
+			t.Fatalf("Failed to look up %s: %v", name, err)
+		}
+		if def.Name != name {
+			t.Errorf("Expected name %s, got %s", name, def.Name)
+		}
+		if def.New == nil {
+			t.Errorf("Engine %s has no constructor", name)
+		}
+	}
+}
+
+func TestEngineRegistryAliases(t *testing.T) {
+	def, err := engines.Lookup("PostgreSQL")
+	if err != nil {
+		t.Fatalf("Failed to look up alias: %v", err)
+	}
+	if def.Name != "postgres" {
+		t.Errorf("Expected alias to resolve to postgres, got %s", def.Name)
+	}
+}
+
+func TestEngineRegistryUnknown(t *testing.T) {
+	if _, err := engines.Lookup("oracle"); err == nil {
+		t.Error("Expected error for unknown engine")
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+)
+
+func TestSplitSQLEscapeStrings(t *testing.T) {
+	script := "INSERT INTO notes VALUES (E'it\\'s; fine');\nSELECT 'a\\';\nSELECT note';'\n"
+	var texts []string
+	for _, statement := range engines.SplitSQL(script, false) {
+		texts = append(texts, statement.Text)
+	}
+
+	// Only E'' strings take backslash escapes; an identifier ending in e does not start one
+	want := []string{"INSERT INTO notes VALUES (E'it\\'s; fine')", "SELECT 'a\\'", "SELECT note';'"}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("Expected %q, got %q", want, texts)
+	}
+}
+
+func TestSplitSQLCopyData(t *testing.T) {
+	script := "CREATE TABLE items (name text, size int);\n" +
+		"COPY public.items (name, size) FROM stdin;\n" +
+		"a;b\t1\n" +
+		"\\N\t2\n" +
+		"\\.\n" +
+		"SELECT 1;\n"
+
+	statements := engines.SplitSQL(script, false)
+	if len(statements) != 3 {
+		t.Fatalf("Expected 3 statements, got %+v", statements)
+	}
+	copyStatement := statements[1]
+	if copyStatement.Text != "COPY public.items (name, size) FROM stdin" || copyStatement.Line != 2 {
+		t.Errorf("Unexpected COPY statement %+v", copyStatement)
+	}
+	if want := []string{"a;b\t1", "\\N\t2"}; !reflect.DeepEqual(copyStatement.CopyRows, want) {
+		t.Errorf("Expected COPY rows %q, got %q", want, copyStatement.CopyRows)
+	}
+	if statements[2].Text != "SELECT 1" || statements[2].Line != 6 {
+		t.Errorf("Expected the script to go on after the data, got %+v", statements[2])
+	}
+
+	// MySQL has no COPY, so its data would be split as statements
+	if statements := engines.SplitSQL(script, true); len(statements) < 4 {
+		t.Errorf("Expected no COPY handling for MySQL, got %+v", statements)
+	}
+}
+
+func TestSplitSQLDelimiter(t *testing.T) {
+	script := "CREATE TABLE items (n int);\n" +
+		"DELIMITER ;;\n" +
+		"CREATE TRIGGER bump BEFORE INSERT ON items FOR EACH ROW BEGIN\n" +
+		"  SET NEW.n = NEW.n + 1;\n" +
+		"END ;;\n" +
+		"delimiter ;\n" +
+		"INSERT INTO items VALUES (1);\n"
+
+	statements := engines.SplitSQL(script, true)
+	var texts []string
+	for _, statement := range statements {
+		texts = append(texts, statement.Text)
+	}
+	want := []string{
+		"CREATE TABLE items (n int)",
+		"CREATE TRIGGER bump BEFORE INSERT ON items FOR EACH ROW BEGIN\n  SET NEW.n = NEW.n + 1;\nEND",
+		"INSERT INTO items VALUES (1)",
+	}
+	if !reflect.DeepEqual(texts, want) {
+		t.Fatalf("Expected %q, got %q", want, texts)
+	}
+	if statements[1].Line != 3 || statements[2].Line != 7 {
+		t.Errorf("Unexpected statement lines %d and %d", statements[1].Line, statements[2].Line)
+	}
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+func TestShellCommandCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake clients are shell scripts")
+	}
+
+	cache := t.TempDir()
+	t.Setenv(utils.CacheEnv, cache)
+	t.Setenv("PATH", "")
+
+	// A client bundled with the downloaded binaries is found without PATH
+	bundled := filepath.Join(cache, "mysql", "bin", "mysql")
+	os.MkdirAll(filepath.Dir(bundled), 0755)
+	os.WriteFile(bundled, []byte("#!/bin/sh\n"), 0755)
+
+	instance := &types.Instance{Port: 3307, Username: "root", Password: `se"cret`}
+	cmd, cleanup, err := engines.NewMySQLEngine(t.TempDir()).ShellCommand(instance, "SELECT 1")
+	if err != nil {
+		t.Fatalf("Failed to build mysql shell: %v", err)
+	}
+	if cmd.Path != bundled {
+		t.Errorf("Expected the bundled client, got %s", cmd.Path)
+	}
+	if strings.Contains(strings.Join(cmd.Args, " "), "cret") {
+		t.Errorf("Expected no password on the command line, got %v", cmd.Args)
+	}
+	optionFile := strings.TrimPrefix(cmd.Args[1], "--defaults-extra-file=")
+	options, err := os.ReadFile(optionFile)
+	if err != nil || !strings.Contains(string(options), `password="se\"cret"`) {
+		t.Errorf("Expected the password in the option file, got %q (%v)", options, err)
+	}
+	if cmd.Args[len(cmd.Args)-2] != "-e" || cmd.Args[len(cmd.Args)-1] != "SELECT 1" {
+		t.Errorf("Expected the query to be passed with -e, got %v", cmd.Args)
+	}
+	cleanup()
+	if _, err := os.Stat(optionFile); !os.IsNotExist(err) {
+		t.Error("Expected cleanup to remove the option file")
+	}
+
+	// Otherwise the client on PATH is used
+	pathDir := t.TempDir()
+	os.WriteFile(filepath.Join(pathDir, "redis-cli"), []byte("#!/bin/sh\n"), 0755)
+	t.Setenv("PATH", pathDir)
+
+	instance = &types.Instance{Port: 6380, Password: "secret"}
+	cmd, cleanup, err = engines.NewRedisEngine(t.TempDir()).ShellCommand(instance, `SET greeting "hello world"`)
+	if err != nil {
+		t.Fatalf("Failed to build redis shell: %v", err)
+	}
+	defer cleanup()
+	if got := strings.Join(cmd.Args[len(cmd.Args)-3:], "|"); got != "SET|greeting|hello world" {
+		t.Errorf("Expected the query split into arguments, got %v", cmd.Args)
+	}
+	found := false
+	for _, env := range cmd.Env {
+		found = found || env == "REDISCLI_AUTH=secret"
+	}
+	if !found {
+		t.Error("Expected the password in REDISCLI_AUTH")
+	}
+
+	// Without either, the error says what is missing
+	t.Setenv("PATH", "")
+	if _, _, err := engines.NewPostgresEngine(t.TempDir()).ShellCommand(instance, ""); err == nil || !strings.Contains(err.Error(), "psql") {
+		t.Errorf("Expected a missing psql error, got %v", err)
+	}
+}
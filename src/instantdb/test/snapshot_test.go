@@ -0,0 +1,100 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/engines"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+func TestSnapshotLifecycle(t *testing.T) {
+	t.Setenv(utils.HomeEnv, t.TempDir())
+
+	dataDir := filepath.Join(t.TempDir(), "data")
+	if err := os.MkdirAll(filepath.Join(dataDir, "base"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "base", "table"), []byte("before"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	instance := &types.Instance{ID: utils.GenerateID(), Name: "shop", Engine: "postgres", Version: "15.3.0", DataDir: dataDir}
+	snapshot, err := utils.CreateSnapshot(instance, "clean")
+	if err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	if snapshot.Size != int64(len("before")) || snapshot.Engine != "postgres" || snapshot.Version != "15.3.0" {
+		t.Errorf("Unexpected snapshot metadata: %+v", snapshot)
+	}
+
+	if _, err := utils.CreateSnapshot(instance, "clean"); err == nil {
+		t.Error("Expected a second snapshot with the same name to be rejected")
+	}
+
+	// Names resolve to the snapshot, as do ID prefixes
+	for _, ref := range []string{"clean", snapshot.ID[:8]} {
+		resolved, err := utils.ResolveSnapshot(ref, instance.ID)
+		if err != nil || resolved.ID != snapshot.ID {
+			t.Errorf("Expected %q to resolve to %s, got %v (%v)", ref, snapshot.ID, resolved, err)
+		}
+	}
+
+	// Restoring brings back the copied data and drops newer files
+	os.WriteFile(filepath.Join(dataDir, "base", "table"), []byte("after"), 0600)
+	os.WriteFile(filepath.Join(dataDir, "new"), []byte("x"), 0600)
+	snapshotData, err := utils.SnapshotDataDir(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.ReplaceDir(dataDir, snapshotData); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dataDir, "base", "table")); string(data) != "before" {
+		t.Errorf("Expected restored content, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "new")); !os.IsNotExist(err) {
+		t.Error("Expected files created after the snapshot to be gone")
+	}
+	if info, err := os.Stat(filepath.Join(dataDir, "base")); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Expected directory permissions to be kept, got %v (%v)", info.Mode(), err)
+	}
+
+	if err := utils.RemoveSnapshot(snapshot); err != nil {
+		t.Fatalf("Failed to remove snapshot: %v", err)
+	}
+	snapshots, err := utils.ListSnapshots()
+	if err != nil || len(snapshots) != 0 {
+		t.Errorf("Expected no snapshots after removal, got %d (%v)", len(snapshots), err)
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	cases := map[string]string{"15.3.0": "15", "8.0.40": "8", "7": "7", "": ""}
+	for version, want := range cases {
+		if got := utils.MajorVersion(version); got != want {
+			t.Errorf("MajorVersion(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
+
+func TestDataVersion(t *testing.T) {
+	mysql, _ := engines.Lookup("mysql")
+	if mysql.DataVersion("8.0.40") == mysql.DataVersion("8.4.3") {
+		t.Error("Expected MySQL 8.0 and 8.4 data to be incompatible")
+	}
+	if mysql.DataVersion("8.4.0") != mysql.DataVersion("8.4.3") {
+		t.Error("Expected MySQL 8.4 patch releases to share data")
+	}
+
+	postgres, _ := engines.Lookup("postgres")
+	if postgres.DataVersion("16.1.0") != postgres.DataVersion("16.4.0") || postgres.DataVersion("16.4.0") == postgres.DataVersion("17.0.0") {
+		t.Error("Expected PostgreSQL data to be tied to the major version")
+	}
+
+	if got := utils.VersionPrefix("8", 2); got != "8" {
+		t.Errorf("VersionPrefix(\"8\", 2) = %q, want \"8\"", got)
+	}
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+func TestLiveStatus(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("HOME", t.TempDir())
+
+	engine := setupTestEngine(t, "redis")
+	dataDir := t.TempDir()
+
+	port, err := utils.GetFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get free port: %v", err)
+	}
+
+	instance := &types.Instance{
+		ID:      utils.GenerateID(),
+		Name:    "test-status",
+		Engine:  "redis",
+		Port:    port,
+		DataDir: dataDir,
+		Status:  "running",
+	}
+	save := func() {
+		if err := utils.SaveInstance(instance); err != nil {
+			t.Fatalf("Failed to save instance: %v", err)
+		}
+	}
+	expectState := func(want string) {
+		t.Helper()
+		status, err := engine.Status(ctx, instance.ID)
+		if err != nil {
+			t.Fatalf("Failed to get status: %v", err)
+		}
+		if status.State != want {
+			t.Errorf("Expected state %s, got %s (%s)", want, status.State, status.Message)
+		}
+	}
+
+	// Nothing is listening on the recorded port
+	save()
+	expectState(types.StateCrashed)
+
+	// Paused instances are not expected to be listening
+	instance.Paused = true
+	save()
+	expectState(types.StatePaused)
+
+	// A foreign process that does not speak the protocol holds the port
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	instance.Paused = false
+	save()
+	expectState(types.StatePortTaken)
+
+	// The data directory was removed behind our back
+	os.RemoveAll(dataDir)
+	instance.DataDir = filepath.Join(dataDir, "missing")
+	save()
+	expectState(types.StateDataMissing)
+}
@@ -0,0 +1,153 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/types"
+	"github.com/db-toolkit/instant-db/src/instantdb/internal/utils"
+)
+
+func TestReserveInstanceConcurrent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	const starts = 10
+	var wg sync.WaitGroup
+	errs := make([]error, starts)
+	instances := make([]*types.Instance, starts)
+
+	for i := 0; i < starts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			instances[i] = &types.Instance{ID: utils.GenerateID(), Name: "shared-name"}
+			errs[i] = utils.ReserveInstance(instances[i])
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("Expected exactly one reservation of a name to succeed, got %d", succeeded)
+	}
+
+	// Distinct names get distinct ports
+	ports := make(map[int]bool)
+	for i := 0; i < starts; i++ {
+		instance := &types.Instance{ID: utils.GenerateID(), Name: fmt.Sprintf("test-%d", i)}
+		if err := utils.ReserveInstance(instance); err != nil {
+			t.Fatalf("Failed to reserve instance: %v", err)
+		}
+		if ports[instance.Port] {
+			t.Errorf("Port %d was reserved twice", instance.Port)
+		}
+		ports[instance.Port] = true
+	}
+}
+
+func TestRepairCorruptedInstance(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	instance := &types.Instance{ID: utils.GenerateID(), Name: "test-repair", Port: 5555}
+	if err := utils.SaveInstance(instance); err != nil {
+		t.Fatalf("Failed to save instance: %v", err)
+	}
+	instance.Port = 6666
+	if err := utils.SaveInstance(instance); err != nil {
+		t.Fatalf("Failed to save instance: %v", err)
+	}
+
+	// Simulate a crash in the middle of a write
+	path := filepath.Join(home, ".instant-db", instance.ID+".json")
+	if err := os.WriteFile(path, []byte(`{"ID": "`), 0644); err != nil {
+		t.Fatalf("Failed to corrupt record: %v", err)
+	}
+
+	records, err := utils.CheckInstances()
+	if err != nil {
+		t.Fatalf("Failed to check instances: %v", err)
+	}
+	if len(records) != 1 || !records[0].HasBackup {
+		t.Fatalf("Expected one corrupted record with a backup, got %+v", records)
+	}
+
+	moved, err := utils.RepairInstance(records[0])
+	if err != nil || moved != "" {
+		t.Fatalf("Expected record to be restored, got %q, %v", moved, err)
+	}
+
+	restored, err := utils.LoadInstance(instance.ID)
+	if err != nil {
+		t.Fatalf("Failed to load restored instance: %v", err)
+	}
+	if restored.Port != 5555 {
+		t.Errorf("Expected previous version from backup, got port %d", restored.Port)
+	}
+
+	// Without a backup the record is quarantined
+	os.Remove(path + ".bak")
+	os.WriteFile(path, []byte("garbage"), 0644)
+
+	records, _ = utils.CheckInstances()
+	if len(records) != 1 || records[0].HasBackup {
+		t.Fatalf("Expected one corrupted record without a backup, got %+v", records)
+	}
+	moved, err = utils.RepairInstance(records[0])
+	if err != nil || moved == "" {
+		t.Fatalf("Expected record to be moved, got %q, %v", moved, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Corrupted record was not removed")
+	}
+}
+
+func TestMigrateLegacyInstance(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// A record written before the schema was versioned
+	dir := filepath.Join(home, ".instant-db")
+	os.MkdirAll(dir, 0755)
+	path := filepath.Join(dir, "legacy1.json")
+	legacy := `{"ID": "legacy1", "Name": "old", "Engine": "redis", "Port": 6380, "Paused": true}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write record: %v", err)
+	}
+
+	instance, err := utils.LoadInstance("legacy1")
+	if err != nil {
+		t.Fatalf("Failed to load legacy instance: %v", err)
+	}
+	if instance.SchemaVersion != utils.CurrentSchemaVersion || instance.RestartPolicy != "no" || instance.Status != "paused" {
+		t.Errorf("Legacy instance was not migrated: %+v", instance)
+	}
+
+	upgraded, err := utils.MigrateInstances()
+	if err != nil || upgraded != 1 {
+		t.Fatalf("Expected one upgraded record, got %d, %v", upgraded, err)
+	}
+	if upgraded, _ := utils.MigrateInstances(); upgraded != 0 {
+		t.Errorf("Expected migration to be idempotent, upgraded %d", upgraded)
+	}
+
+	// Records from a newer release are refused but not reported as corrupted
+	newer := fmt.Sprintf(`{"ID": "newer1", "SchemaVersion": %d}`, utils.CurrentSchemaVersion+1)
+	os.WriteFile(filepath.Join(dir, "newer1.json"), []byte(newer), 0644)
+
+	if _, err := utils.LoadInstance("newer1"); !errors.Is(err, utils.ErrNewerSchema) {
+		t.Errorf("Expected ErrNewerSchema, got %v", err)
+	}
+	if records, _ := utils.CheckInstances(); len(records) != 0 {
+		t.Errorf("Expected no corrupted records, got %+v", records)
+	}
+}